@@ -0,0 +1,206 @@
+package ftl
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Schedule computes how long to wait before the n'th retry,
+// counting from 0. prev is how long was waited before the last one.
+type Schedule func(n int, prev time.Duration) time.Duration
+
+// Constant waits d before every retry.
+func Constant(d time.Duration) Schedule {
+	return func(_ int, _ time.Duration) time.Duration {
+		return d
+	}
+}
+
+// Linear waits start before the first retry, and step longer before
+// each one after that.
+func Linear(start, step time.Duration) Schedule {
+	return func(n int, _ time.Duration) time.Duration {
+		return sat(float64(start) + float64(n)*float64(step))
+	}
+}
+
+// Exponential waits start before the first retry, and twice as long
+// before each one after that.
+func Exponential(start time.Duration) Schedule {
+	return func(n int, _ time.Duration) time.Duration {
+		return sat(float64(start) * math.Pow(2, float64(n)))
+	}
+}
+
+// Fibonacci waits unit before the first and second retries, and the
+// sum of the last two waits before each one after that.
+func Fibonacci(unit time.Duration) Schedule {
+	return func(n int, _ time.Duration) time.Duration {
+		a, b := 1.0, 1.0
+		for i := 0; i < n && a < math.MaxInt64; i++ {
+			a, b = b, a+b
+		}
+		return sat(float64(unit) * a)
+	}
+}
+
+// Decorrelated waits a random duration between base and three times
+// the last wait, up to ceil. This is the 'decorrelated jitter'
+// schedule.
+func Decorrelated(base, ceil time.Duration) Schedule {
+	return func(_ int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+		d := base + jitter(sat(3*float64(prev))-base)
+		if d > ceil {
+			d = ceil
+		}
+		return d
+	}
+}
+
+// Ceil caps the waits of s at d.
+func (s Schedule) Ceil(d time.Duration) Schedule {
+	return func(n int, prev time.Duration) time.Duration {
+		if x := s(n, prev); x < d {
+			return x
+		}
+		return d
+	}
+}
+
+// FullJitter waits a random duration between 0 and what s would.
+func (s Schedule) FullJitter() Schedule {
+	return func(n int, prev time.Duration) time.Duration {
+		return jitter(s(n, prev))
+	}
+}
+
+// EqualJitter waits half of what s would, plus a random duration
+// between 0 and the other half.
+func (s Schedule) EqualJitter() Schedule {
+	return func(n int, prev time.Duration) time.Duration {
+		d := s(n, prev)
+		return d/2 + jitter(d-d/2)
+	}
+}
+
+// Backoff returns a predicate that waits according to s every time
+// it's evaluated, and then holds. If ctx is cancelled while waiting,
+// it doesn't hold.
+//
+// Errors marked with Permanent make it not hold right away, and
+// errors marked with RetryAfter make it wait exactly as long as they
+// say to, instead of following s.
+//
+// It's meant to be used with While, as in:
+//
+//	f.While(NotNil().And(Exponential(ms).FullJitter().Backoff(ctx)))
+//
+// Under Until, use BackoffUntil instead.
+func (s Schedule) Backoff(ctx context.Context) Predicate {
+	return s.BackoffFor(ctx, -1)
+}
+
+// BackoffFor is like Backoff, but also doesn't hold if waiting would
+// take it past max since it was first evaluated. A max < 0 means no
+// limit.
+func (s Schedule) BackoffFor(ctx context.Context, max time.Duration) Predicate {
+	var (
+		mu      sync.Mutex
+		n       int
+		prev    time.Duration
+		start   time.Time
+		started bool
+	)
+	permanent := IsPermanent()
+	return func(err error) bool {
+		if permanent(err) {
+			return false
+		}
+
+		mu.Lock()
+		if !started {
+			start, started = time.Now(), true
+		}
+		since := start
+		d, hinted := retryAfter(err)
+		if !hinted {
+			d = s(n, prev)
+			n++
+			prev = d
+		}
+		mu.Unlock()
+
+		if max >= 0 && time.Since(since)+d > max {
+			return false
+		}
+		return wait(ctx, d)
+	}
+}
+
+// BackoffUntil is Backoff for use with Until, as in:
+//
+//	f.Until(Exponential(ms).FullJitter().BackoffUntil(ctx))
+//
+// It holds, ending the loop, on success, on errors marked with
+// Permanent, and whenever Backoff wouldn't. Otherwise it waits like
+// Backoff, and doesn't hold.
+func (s Schedule) BackoffUntil(ctx context.Context) Predicate {
+	return giveUp(s.Backoff(ctx))
+}
+
+// BackoffUntilFor is BackoffFor for use with Until, like
+// BackoffUntil.
+func (s Schedule) BackoffUntilFor(ctx context.Context, max time.Duration) Predicate {
+	return giveUp(s.BackoffFor(ctx, max))
+}
+
+// giveUp turns a backoff predicate meant for While into one meant
+// for Until, that also holds on success.
+func giveUp(backoff Predicate) Predicate {
+	return func(err error) bool {
+		return err == nil || !backoff(err)
+	}
+}
+
+// wait for d, returning false if ctx is cancelled before then.
+func wait(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// jitter returns a random duration between 0 and d.
+func jitter(d time.Duration) time.Duration {
+	switch {
+	case d <= 0:
+		return 0
+	case d == math.MaxInt64:
+		return time.Duration(rand.Int63())
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// sat converts x to a duration, saturating instead of overflowing.
+func sat(x float64) time.Duration {
+	switch {
+	case x >= math.MaxInt64:
+		return math.MaxInt64
+	case x <= 0:
+		return 0
+	}
+	return time.Duration(x)
+}
@@ -0,0 +1,162 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waits(s Schedule, n int) []time.Duration {
+	var (
+		ds   = make([]time.Duration, n)
+		prev time.Duration
+	)
+	for i := range ds {
+		ds[i] = s(i, prev)
+		prev = ds[i]
+	}
+	return ds
+}
+
+func TestSchedule(t *testing.T) {
+	t.Run("Policies", func(t *testing.T) {
+		assert.Equal(t, []time.Duration{3, 3, 3}, waits(Constant(3), 3))
+		assert.Equal(t, []time.Duration{1, 3, 5, 7}, waits(Linear(1, 2), 4))
+		assert.Equal(t, []time.Duration{1, 2, 4, 8, 10}, waits(Exponential(1).Ceil(10), 5))
+		assert.Equal(t, []time.Duration{1, 1, 2, 3, 5, 8}, waits(Fibonacci(1), 6))
+		assert.Equal(t, time.Duration(math.MaxInt64), Exponential(time.Second)(200, 0))
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			d := Constant(100).FullJitter()(0, 0)
+			assert.True(t, d >= 0 && d <= 100)
+
+			d = Constant(100).EqualJitter()(0, 0)
+			assert.True(t, d >= 50 && d <= 100)
+
+			d = Decorrelated(10, 50)(0, 40)
+			assert.True(t, d >= 10 && d <= 50)
+		}
+	})
+
+	t.Run("Backoff cancels", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := Fail(errors.New("x")).While(Constant(time.Hour).Backoff(ctx))()
+		assert.Error(t, err)
+		assert.True(t, time.Since(start) < time.Hour)
+	})
+
+	t.Run("BackoffFor", func(t *testing.T) {
+		var i int
+		err := Closure(fail(&i)).While(Constant(5*time.Millisecond).BackoffFor(context.Background(), 12*time.Millisecond))()
+		assert.Error(t, err)
+		assert.Equal(t, 3, i)
+	})
+}
+
+func TestMarkers(t *testing.T) {
+	errX := errors.New("x")
+
+	t.Run("Permanent stops", func(t *testing.T) {
+		var i int
+		err := Tasklet(func(_ context.Context) error {
+			i++
+			if i == 2 {
+				return Permanent(errX)
+			}
+			return errX
+		}).While(Constant(time.Millisecond).Backoff(context.Background())).Run()
+		assert.True(t, errors.Is(err, errX))
+		assert.True(t, IsPermanent()(err))
+		assert.Equal(t, 2, i)
+
+		i = 0
+		err = Closure(func() error {
+			i++
+			return Permanent(errX)
+		}).While(Backoff(time.Millisecond, time.Second))()
+		assert.True(t, IsPermanent()(err))
+		assert.Equal(t, 1, i)
+	})
+
+	t.Run("Permanent stops Until", func(t *testing.T) {
+		var i int
+		err := Tasklet(func(_ context.Context) error {
+			i++
+			if i == 2 {
+				return Permanent(errX)
+			}
+			return errX
+		}).Until(Constant(time.Millisecond).BackoffUntil(context.Background())).Run()
+		assert.True(t, IsPermanent()(err))
+		assert.Equal(t, 2, i)
+
+		i = 0
+		err = Closure(func() error {
+			i++
+			if i == 3 {
+				return Permanent(errX)
+			}
+			return errX
+		}).Until(BackoffUntil(time.Millisecond, time.Second))()
+		assert.True(t, IsPermanent()(err))
+		assert.Equal(t, 3, i)
+	})
+
+	t.Run("Until stops on success", func(t *testing.T) {
+		var i int
+		err := Closure(func() error {
+			i++
+			if i == 1 {
+				return RetryAfter(errX, 20*time.Millisecond)
+			}
+			return nil
+		}).Until(Constant(time.Hour).BackoffUntil(context.Background()))()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, i)
+	})
+
+	t.Run("RetryAfter counts toward max", func(t *testing.T) {
+		var i int
+		start := time.Now()
+		err := Closure(func() error {
+			i++
+			return RetryAfter(errX, 10*time.Millisecond)
+		}).Until(Constant(0).BackoffUntilFor(context.Background(), 30*time.Millisecond))()
+		assert.Error(t, err)
+		assert.True(t, time.Since(start) < 100*time.Millisecond)
+		assert.True(t, i <= 4)
+	})
+
+	t.Run("RetryAfter waits", func(t *testing.T) {
+		var i int
+		start := time.Now()
+		err := Closure(func() error {
+			i++
+			if i == 1 {
+				return RetryAfter(errX, 20*time.Millisecond)
+			}
+			return nil
+		}).While(NotNil().And(Constant(time.Hour).Backoff(context.Background())))()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, i)
+		assert.True(t, time.Since(start) >= 20*time.Millisecond)
+		assert.True(t, time.Since(start) < time.Hour)
+	})
+
+	t.Run("Classified", func(t *testing.T) {
+		c := Classify(ClassRetryable)
+		assert.Equal(t, ClassPermanent, c(Permanent(errX)))
+		assert.Equal(t, ClassThrottled, c(RetryAfter(errX, time.Second)))
+		assert.Equal(t, ClassRetryable, c(errX))
+		assert.Nil(t, Permanent(nil))
+	})
+}
@@ -0,0 +1,52 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBracket(t *testing.T) {
+	errX, errY := errors.New("x"), errors.New("y")
+
+	t.Run("Release after failure", func(t *testing.T) {
+		var acquired, released int
+		err := Closure.Bracket(succ(&acquired), Fail(errX), succ(&released))()
+		assert.Equal(t, errX, err)
+		assert.Equal(t, 1, released)
+	})
+
+	t.Run("No release without acquire", func(t *testing.T) {
+		var used, released int
+		err := Closure.Bracket(Fail(errX), succ(&used), succ(&released))()
+		assert.Equal(t, errX, err)
+		assert.Equal(t, 0, used)
+		assert.Equal(t, 0, released)
+	})
+
+	t.Run("Errors combine", func(t *testing.T) {
+		err := Fail(errX).Finally(Fail(errY))()
+		assert.Equal(t, MultiError{{0, errX}, {1, errY}}, err)
+	})
+
+	t.Run("Release after cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var releaseErr error = errX
+		err := Tasklet.Bracket(
+			retErr,
+			func(_ context.Context) error { cancel(); return ctx.Err() },
+			func(rctx context.Context) error { releaseErr = rctx.Err(); return nil },
+		)(ctx)
+		assert.Equal(t, context.Canceled, err)
+		assert.NoError(t, releaseErr)
+	})
+
+	t.Run("Release after panic", func(t *testing.T) {
+		var released int
+		err := Closure(func() error { panic("boom") }).Finally(succ(&released)).Recover()()
+		assert.True(t, Panicked()(err))
+		assert.Equal(t, 1, released)
+	})
+}
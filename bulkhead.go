@@ -0,0 +1,181 @@
+package ftl
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BulkheadFullError is returned in place of calling a function
+// through a Bulkhead that had no room left to queue the call.
+type BulkheadFullError struct {
+	// Weight of the rejected call.
+	Weight int64
+
+	// Queued is the number of calls that were waiting already.
+	Queued int
+}
+
+func (e *BulkheadFullError) Error() string {
+	return fmt.Sprintf("ftl: bulkhead full (weight %d, %d queued)",
+		e.Weight, e.Queued)
+}
+
+// BulkheadStats describes what a Bulkhead has been up to.
+type BulkheadStats struct {
+	// InFlight is the total weight of running calls.
+	InFlight int64
+
+	// Queued is the number of calls waiting to run.
+	Queued int
+
+	// Rejected is the number of calls that were rejected.
+	Rejected uint64
+
+	// Waited is the number of calls that had to queue, whether or
+	// not they got to run in the end.
+	Waited uint64
+
+	// QueueTime is the total time calls spent queued.
+	QueueTime time.Duration
+
+	// MaxQueueTime is the longest time any call spent queued.
+	MaxQueueTime time.Duration
+}
+
+// Bulkhead is a weighted semaphore that limits how many calls run
+// at once, and how many may queue up waiting to run. Queued calls
+// run in the order they arrived.
+//
+// A bulkhead can be shared by any number of functions, which are
+// then limited together.
+type Bulkhead struct {
+	mu       sync.Mutex
+	size     int64
+	cur      int64
+	maxQueue int
+	waiters  list.List // of *waiter
+	stats    BulkheadStats
+}
+
+type waiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewBulkhead returns a bulkhead that runs calls weighing up to
+// maxConcurrent in total at once, and queues up to maxQueue calls.
+func NewBulkhead(maxConcurrent int64, maxQueue int) *Bulkhead {
+	return &Bulkhead{
+		size:     maxConcurrent,
+		maxQueue: maxQueue,
+	}
+}
+
+// Stats returns a snapshot of the bulkhead's stats.
+func (b *Bulkhead) Stats() BulkheadStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.stats
+	s.InFlight = b.cur
+	s.Queued = b.waiters.Len()
+	return s
+}
+
+// Acquire n slots, waiting in the queue if there aren't enough. If
+// the queue is full, or n is more than the bulkhead could ever hold,
+// a *BulkheadFullError is returned. If ctx is cancelled while
+// waiting, it gives up and returns ctx.Err().
+func (b *Bulkhead) Acquire(ctx context.Context, n int64) error {
+	b.mu.Lock()
+	if b.cur+n <= b.size && b.waiters.Len() == 0 {
+		b.cur += n
+		b.mu.Unlock()
+		return nil
+	}
+	if n > b.size || b.waiters.Len() >= b.maxQueue {
+		err := &BulkheadFullError{n, b.waiters.Len()}
+		b.stats.Rejected++
+		b.mu.Unlock()
+		return err
+	}
+
+	w := &waiter{n: n, ready: make(chan struct{})}
+	elem := b.waiters.PushBack(w)
+	b.stats.Waited++
+	b.mu.Unlock()
+
+	start := time.Now()
+	var err error
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	d := time.Since(start)
+	b.stats.QueueTime += d
+	if d > b.stats.MaxQueueTime {
+		b.stats.MaxQueueTime = d
+	}
+
+	if err != nil {
+		select {
+		case <-w.ready:
+			// acquired just as we gave up; hand it back
+			b.cur -= n
+		default:
+			b.waiters.Remove(elem)
+		}
+		b.wake()
+	}
+	return err
+}
+
+// Release n slots.
+func (b *Bulkhead) Release(n int64) {
+	b.mu.Lock()
+	b.cur -= n
+	b.wake()
+	b.mu.Unlock()
+}
+
+// wake queued calls that fit, in order. Must be called with mu held.
+func (b *Bulkhead) wake() {
+	for {
+		front := b.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*waiter)
+		if b.cur+w.n > b.size {
+			return
+		}
+		b.cur += w.n
+		b.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// Bulkhead runs f through b, taking up weight slots while it runs.
+func (f Tasklet) Bulkhead(b *Bulkhead, weight int64) Tasklet {
+	return func(ctx context.Context) error {
+		if err := b.Acquire(ctx, weight); err != nil {
+			return err
+		}
+		defer b.Release(weight)
+		return f(ctx)
+	}
+}
+
+// Bulkhead is like Tasklet.Bulkhead.
+func (f Routine) Bulkhead(b *Bulkhead, weight int64) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Ap2(state).Bulkhead(b, weight)(ctx)
+	}
+}
@@ -0,0 +1,65 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkhead(t *testing.T) {
+	t.Run("Rejects past the queue", func(t *testing.T) {
+		b := NewBulkhead(2, 1)
+		block := make(chan struct{})
+		held := Tasklet(func(_ context.Context) error {
+			<-block
+			return nil
+		}).Bulkhead(b, 2)
+
+		done := make(chan error, 2)
+		go func() { done <- held.Run() }()
+		for b.Stats().InFlight != 2 {
+			time.Sleep(time.Millisecond)
+		}
+		go func() { done <- retErr.Bulkhead(b, 1).Run() }()
+		for b.Stats().Queued != 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		err := retErr.Bulkhead(b, 1).Run()
+		var full *BulkheadFullError
+		assert.True(t, errors.As(err, &full))
+		assert.Equal(t, 1, full.Queued)
+
+		close(block)
+		assert.NoError(t, <-done)
+		assert.NoError(t, <-done)
+
+		s := b.Stats()
+		assert.Equal(t, int64(0), s.InFlight)
+		assert.Equal(t, uint64(1), s.Rejected)
+		assert.Equal(t, uint64(1), s.Waited)
+		assert.True(t, s.MaxQueueTime > 0)
+	})
+
+	t.Run("Too heavy", func(t *testing.T) {
+		b := NewBulkhead(2, 10)
+		_, ok := retErr.Bulkhead(b, 3).Run().(*BulkheadFullError)
+		assert.True(t, ok)
+	})
+
+	t.Run("Waiting cancels", func(t *testing.T) {
+		b := NewBulkhead(1, 10)
+		assert.NoError(t, b.Acquire(context.Background(), 1))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, retErr.Bulkhead(b, 1)(ctx))
+		assert.Equal(t, 0, b.Stats().Queued)
+
+		b.Release(1)
+		assert.NoError(t, retErr.Bulkhead(b, 1).Run())
+	})
+}
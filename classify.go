@@ -0,0 +1,148 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Is holds for errors that match target according to errors.Is.
+func Is(target error) Predicate {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// As holds for errors that have an error of type T in their chain,
+// according to errors.As.
+func As[T error]() Predicate {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// Matches holds for errors whose message matches re.
+func Matches(re *regexp.Regexp) Predicate {
+	return func(err error) bool {
+		return err != nil && re.MatchString(err.Error())
+	}
+}
+
+// Timeout holds for errors that are ErrTimeout, context.DeadlineExceeded,
+// or that report being a timeout like net.Error does.
+func Timeout() Predicate {
+	return func(err error) bool {
+		if errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+		var t interface{ Timeout() bool }
+		return errors.As(err, &t) && t.Timeout()
+	}
+}
+
+// Temporary holds for errors that report being temporary like
+// net.Error does.
+func Temporary() Predicate {
+	return func(err error) bool {
+		var t interface{ Temporary() bool }
+		return errors.As(err, &t) && t.Temporary()
+	}
+}
+
+// IsPermanent holds for errors marked with Permanent.
+func IsPermanent() Predicate {
+	return As[*PermanentError]()
+}
+
+// IsRetryAfter holds for errors marked with RetryAfter.
+func IsRetryAfter() Predicate {
+	return As[*RetryAfterError]()
+}
+
+// Class is a broad category of errors, by what should be done about
+// them.
+type Class uint8
+
+const (
+	// ClassNone is the class of nil errors.
+	ClassNone Class = iota
+
+	// ClassRetryable errors may go away if retried.
+	ClassRetryable
+
+	// ClassPermanent errors won't go away if retried.
+	ClassPermanent
+
+	// ClassThrottled errors may go away if retried later.
+	ClassThrottled
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassNone:
+		return "none"
+	case ClassRetryable:
+		return "retryable"
+	case ClassPermanent:
+		return "permanent"
+	case ClassThrottled:
+		return "throttled"
+	}
+	return "unknown"
+}
+
+// Classifier sorts errors into classes. nil errors are always of
+// ClassNone.
+type Classifier func(error) Class
+
+// Classify returns a Classifier that puts errors marked with
+// Permanent in ClassPermanent, those marked with RetryAfter in
+// ClassThrottled, and every other error in class def.
+func Classify(def Class) Classifier {
+	permanent, throttled := IsPermanent(), IsRetryAfter()
+	return func(err error) Class {
+		switch {
+		case err == nil:
+			return ClassNone
+		case permanent(err):
+			return ClassPermanent
+		case throttled(err):
+			return ClassThrottled
+		}
+		return def
+	}
+}
+
+// When puts errors for which p holds in class cl, and leaves the
+// rest to c. Errors are checked against the last When first.
+func (c Classifier) When(p Predicate, cl Class) Classifier {
+	return func(err error) Class {
+		if err != nil && p(err) {
+			return cl
+		}
+		return c(err)
+	}
+}
+
+// Class holds for errors of class cl.
+func (c Classifier) Class(cl Class) Predicate {
+	return func(err error) bool {
+		return c(err) == cl
+	}
+}
+
+// Retryable holds for errors of ClassRetryable.
+func (c Classifier) Retryable() Predicate {
+	return c.Class(ClassRetryable)
+}
+
+// Permanent holds for errors of ClassPermanent.
+func (c Classifier) Permanent() Predicate {
+	return c.Class(ClassPermanent)
+}
+
+// Throttled holds for errors of ClassThrottled.
+func (c Classifier) Throttled() Predicate {
+	return c.Class(ClassThrottled)
+}
@@ -0,0 +1,64 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tempErr struct{}
+
+func (tempErr) Error() string   { return "temp" }
+func (tempErr) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	errX := errors.New("x")
+	wrapped := fmt.Errorf("wrapped: %w", errX)
+
+	t.Run("Predicates", func(t *testing.T) {
+		assert.True(t, Error(errX)(wrapped))
+		assert.True(t, Is(errX)(wrapped))
+		assert.False(t, Is(errX)(errors.New("x")))
+
+		assert.True(t, As[*net.OpError]()(fmt.Errorf("dial: %w", &net.OpError{Err: errX})))
+		assert.False(t, As[*net.OpError]()(errX))
+
+		assert.True(t, Matches(regexp.MustCompile("^wrap"))(wrapped))
+		assert.False(t, Matches(regexp.MustCompile("."))(nil))
+
+		assert.True(t, Timeout()(ErrTimeout))
+		assert.True(t, Timeout()(fmt.Errorf("%w", context.DeadlineExceeded)))
+		assert.True(t, Timeout()(&net.DNSError{IsTimeout: true}))
+		assert.False(t, Timeout()(errX))
+
+		assert.True(t, Temporary()(fmt.Errorf("%w", tempErr{})))
+		assert.False(t, Temporary()(errX))
+	})
+
+	t.Run("Classifier", func(t *testing.T) {
+		c := Classify(ClassPermanent).
+			When(Temporary(), ClassRetryable).
+			When(Is(errX), ClassThrottled)
+
+		assert.Equal(t, ClassNone, c(nil))
+		assert.Equal(t, ClassRetryable, c(tempErr{}))
+		assert.Equal(t, ClassThrottled, c(wrapped))
+		assert.Equal(t, ClassPermanent, c(errors.New("y")))
+
+		var i int
+		err := Closure(func() error {
+			i++
+			if i < 3 {
+				return tempErr{}
+			}
+			return errX
+		}).While(c.Retryable())()
+		assert.Equal(t, errX, err)
+		assert.Equal(t, 3, i)
+	})
+}
@@ -1,6 +1,7 @@
 package ftl
 
 import (
+	"context"
 	"sync"
 
 	"golang.org/x/sync/errgroup"
@@ -32,12 +33,15 @@ func (f Closure) Binds(bind func(Closure, Closure) Closure,
 
 func (f Closure) Seq(gs ...Closure) Closure {
 	return func() error {
-		var err error
-		if err = f(); err != nil {
+		var (
+			err error
+			bg  = context.Background()
+		)
+		if err = step(bg, at(StepSeq, 0), f); err != nil {
 			return err
 		}
 		for i := range gs {
-			if err = gs[i](); err != nil {
+			if err = step(bg, at(StepSeq, i+1), gs[i]); err != nil {
 				break
 			}
 		}
@@ -47,19 +51,100 @@ func (f Closure) Seq(gs ...Closure) Closure {
 
 func (f Closure) Par(gs ...Closure) Closure {
 	return func() error {
-		var eg errgroup.Group
-		eg.Go(f)
+		var (
+			eg errgroup.Group
+			bg = context.Background()
+		)
+		eg.Go(f.step(bg, at(StepPar, 0)))
 		for i := range gs {
-			eg.Go(gs[i])
+			eg.Go(gs[i].step(bg, at(StepPar, i+1)))
 		}
 		return eg.Wait()
 	}
 }
 
+// ParN is like Par, but runs at most limit functions at once. A
+// limit <= 0 means no limit.
+func (f Closure) ParN(limit int, gs ...Closure) Closure {
+	return func() error {
+		var eg errgroup.Group
+		goN(context.Background(), nil, &eg, limit, lift(append([]Closure{f}, gs...)...))
+		return eg.Wait()
+	}
+}
+
+// goN runs fs in eg, with at most limit of them running at once. If
+// ctx is cancelled, no further fs are started and goN returns false.
+// If cancel isn't nil, it's called when any f fails.
+func goN(ctx context.Context, cancel context.CancelFunc,
+	eg *errgroup.Group, limit int, fs []Tasklet,
+) bool {
+	if limit <= 0 || limit > len(fs) {
+		limit = len(fs)
+	}
+	sem := make(chan struct{}, limit)
+	for i, f := range fs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		f := f.step(ctx, at(StepPar, i))
+		eg.Go(func() error {
+			defer func() { <-sem }()
+			err := f()
+			if err != nil && cancel != nil {
+				cancel()
+			}
+			return err
+		})
+	}
+	return true
+}
+
+// ParAll is like Par, but waits for every function to return. All
+// of their errors are returned as a MultiError.
+func (f Closure) ParAll(gs ...Closure) Closure {
+	return func() error {
+		return goAll(context.Background(), lift(append([]Closure{f}, gs...)...))
+	}
+}
+
+// goAll runs fs concurrently as the steps of a Par, returning once
+// all of them have. Any errors are collected into a MultiError.
+func goAll(ctx context.Context, fs []Tasklet) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(fs))
+	)
+	for i, f := range fs {
+		wg.Add(1)
+		go func(i int, f Tasklet) {
+			defer wg.Done()
+			errs[i] = f.step(ctx, at(StepPar, i))()
+		}(i, f)
+	}
+	wg.Wait()
+
+	var m MultiError
+	for i, err := range errs {
+		if err != nil {
+			m = append(m, BranchError{i, err})
+		}
+	}
+	if m == nil {
+		return nil
+	}
+	return m
+}
+
 func (f Closure) cond(p Predicate, exit bool) Closure {
 	return func() error {
-		for {
-			if err := f(); p(err) == exit {
+		for n := 1; ; n++ {
+			err := step(context.Background(), attempt(exit, n), f)
+			if p(err) == exit {
 				return err
 			}
 		}
@@ -74,12 +159,29 @@ func (f Closure) Until(p Predicate) Closure {
 	return Closure.cond(f, p, true)
 }
 
+// WhileEach is like While, but uses a fresh predicate from pf every
+// time it runs.
+func (f Closure) WhileEach(pf PredicateFactory) Closure {
+	return func() error {
+		return f.While(pf())()
+	}
+}
+
+// UntilEach is like Until, but uses a fresh predicate from pf every
+// time it runs.
+func (f Closure) UntilEach(pf PredicateFactory) Closure {
+	return func() error {
+		return f.Until(pf())()
+	}
+}
+
 func (f Closure) Ite(p Predicate, g, z Closure) Closure {
 	return func() error {
-		if err := f(); p(err) {
-			return g()
+		bg := context.Background()
+		if err := step(bg, at(StepIte, 0), f); p(err) {
+			return step(bg, at(StepIte, 1), g)
 		} else {
-			return z()
+			return step(bg, at(StepIte, 2), z)
 		}
 	}
 }
@@ -102,3 +204,65 @@ func (f Closure) Once() Closure {
 		return err
 	}
 }
+
+// Recover converts a panic in f into a *PanicError.
+func (f Closure) Recover() Closure {
+	return func() (err error) {
+		defer recovers(&err)
+		return f()
+	}
+}
+
+// Finally runs g after f, even if f fails or panics. If both fail,
+// their errors are returned as a MultiError.
+func (f Closure) Finally(g Closure) Closure {
+	return func() (err error) {
+		bg := context.Background()
+		defer func() { err = join(err, step(bg, at(StepFinally, 1), g)) }()
+		return step(bg, at(StepFinally, 0), f)
+	}
+}
+
+// Bracket runs use and then release if acquire succeeds. release is
+// always run once acquire has succeeded, as with Finally.
+func (acquire Closure) Bracket(use, release Closure) Closure {
+	return func() error {
+		if err := acquire(); err != nil {
+			return err
+		}
+		return use.Finally(release)()
+	}
+}
+
+// OrElse runs f, then each of gs in turn until one succeeds. If they
+// all fail, their errors are returned as a MultiError.
+func (f Closure) OrElse(gs ...Closure) Closure {
+	return f.OrElseIf(NotNil(), gs...)
+}
+
+// OrElseIf is like OrElse, but only falls back to the next function
+// if p holds for the error. Otherwise, that error is returned as is.
+func (f Closure) OrElseIf(p Predicate, gs ...Closure) Closure {
+	return func() error {
+		return orElse(context.Background(), p, lift(append([]Closure{f}, gs...)...))
+	}
+}
+
+// orElse runs fs in turn until one succeeds, or fails with an error
+// for which p doesn't hold. It stops early if ctx is cancelled.
+func orElse(ctx context.Context, p Predicate, fs []Tasklet) error {
+	errs := make(MultiError, 0, len(fs))
+	for i, f := range fs {
+		if i > 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		err := f.step(ctx, at(StepOrElse, i))()
+		if err == nil || !p(err) {
+			return err
+		}
+		errs = append(errs, BranchError{i, err})
+	}
+	return errs
+}
@@ -0,0 +1,186 @@
+package ftl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BranchError is the error returned by one of several functions
+// composed together, along with its position in the composition.
+type BranchError struct {
+	Index int
+	Err   error
+}
+
+func (e BranchError) Error() string {
+	return fmt.Sprintf("[%d] %v", e.Index, e.Err)
+}
+
+func (e BranchError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError holds the errors of several functions composed
+// together, ordered by their position in the composition.
+type MultiError []BranchError
+
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i := range m {
+		msgs[i] = m[i].Error()
+	}
+	return fmt.Sprintf("ftl: %d errors: %s", len(m), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the BranchError of each branch.
+func (m MultiError) Unwrap() []error {
+	errs := make([]error, len(m))
+	for i := range m {
+		errs[i] = m[i]
+	}
+	return errs
+}
+
+// sorted orders m by branch position.
+func (m MultiError) sorted() MultiError {
+	sort.Slice(m, func(i, j int) bool {
+		return m[i].Index < m[j].Index
+	})
+	return m
+}
+
+// join combines the error of a function with that of its cleanup.
+// If both failed, they're returned as a MultiError.
+func join(err, cleanup error) error {
+	switch {
+	case cleanup == nil:
+		return err
+	case err == nil:
+		return cleanup
+	}
+	return MultiError{{0, err}, {1, cleanup}}
+}
+
+// PermanentError marks an error as one that retrying won't fix.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent marks err as one that retrying won't fix. Backoff
+// predicates stop retrying as soon as they see it.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfterError marks an error as one that shouldn't be retried
+// until some time has passed.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+// RetryAfter marks err as one that shouldn't be retried until d has
+// passed. Backoff predicates wait exactly d before the next retry.
+func RetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryAfterError{err, d}
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %v)", e.Err, e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// retryAfter returns the delay hinted by a RetryAfter in the chain
+// of err, if any.
+func retryAfter(err error) (time.Duration, bool) {
+	var r *RetryAfterError
+	if errors.As(err, &r) {
+		return r.After, true
+	}
+	return 0, false
+}
+
+// StepError is the error of a named function, along with the path
+// to it through the compositions it was part of, such as
+// "deploy/seq[2]/par[1]/upload".
+type StepError struct {
+	Path string
+	Err  error
+
+	// inner is the path of a *StepError wrapped within Err, which
+	// is left out of the message since Path includes it.
+	inner string
+}
+
+func (e *StepError) Error() string {
+	msg := e.Err.Error()
+	if e.inner != "" {
+		msg = strings.Replace(msg, e.inner+": ", "", 1)
+	}
+	return e.Path + ": " + msg
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the underlying error, for github.com/pkg/errors.
+func (e *StepError) Cause() error {
+	return e.Err
+}
+
+// hasStep reports whether err is or wraps a *StepError.
+func hasStep(err error) bool {
+	var e *StepError
+	return errors.As(err, &e)
+}
+
+// within prepends segment to the path of every *StepError in err,
+// including those in the branches of a MultiError. Errors without
+// one are returned as is.
+func within(segment string, err error) error {
+	if !hasStep(err) {
+		return err
+	}
+	switch e := err.(type) {
+	case *StepError:
+		return &StepError{segment + "/" + e.Path, e.Err, e.inner}
+	case MultiError:
+		m := make(MultiError, len(e))
+		for i := range e {
+			m[i] = BranchError{e[i].Index, within(segment, e[i].Err)}
+		}
+		return m
+	case BranchError:
+		return BranchError{e.Index, within(segment, e.Err)}
+	case *PermanentError:
+		return &PermanentError{within(segment, e.Err)}
+	case *RetryAfterError:
+		return &RetryAfterError{within(segment, e.Err), e.After}
+	}
+
+	// wrapped by something we can't rebuild, so wrap it again
+	var e *StepError
+	errors.As(err, &e)
+	return &StepError{segment + "/" + e.Path, err, e.Path}
+}
@@ -0,0 +1,255 @@
+package fbreak
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nytopop/ftl"
+)
+
+// ErrOpen is returned in place of calling a function wrapped by an
+// open Breaker.
+var ErrOpen = errors.New("fbreak: breaker is open")
+
+// State of a Breaker.
+type State uint8
+
+const (
+	// Closed breakers let every call through.
+	Closed State = iota
+
+	// Open breakers fail every call with ErrOpen.
+	Open
+
+	// HalfOpen breakers let a few probe calls through, to find out
+	// whether they should close again.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Config of a Breaker.
+type Config struct {
+	// Failure holds for errors that count as failures. If nil,
+	// every non-nil error does.
+	Failure ftl.Predicate
+
+	// Threshold is the number of consecutive failures that opens
+	// the breaker, if Trip is nil. At least 1.
+	Threshold int
+
+	// Trip makes the predicate that decides when to open the
+	// breaker. While closed, it's evaluated after every call with
+	// the error if it was a failure, or nil if it wasn't. A fresh
+	// one is made every time the breaker closes.
+	Trip ftl.PredicateFactory
+
+	// Cooldown is how long the breaker stays open before going
+	// half-open.
+	Cooldown time.Duration
+
+	// Probes is the number of calls let through while half-open,
+	// all of which must succeed to close the breaker again. At
+	// least 1.
+	Probes int
+
+	// OnChange is called whenever the breaker changes state, if
+	// not nil. Calls are made one at a time and in order, though
+	// not necessarily by the goroutine that made the change.
+	OnChange func(from, to State)
+}
+
+// Breaker is a circuit breaker. It keeps track of how calls to the
+// functions it wraps went, and stops calling them for a while once
+// too many have failed.
+type Breaker struct {
+	c Config
+
+	mu       sync.Mutex
+	state    State
+	trip     ftl.Predicate
+	openedAt time.Time
+	round    uint64 // of being half-open, that probes belong to
+	probing  int    // probes in flight
+	probed   int    // probes that succeeded
+
+	changes   []transition // yet to be handed to OnChange
+	notifying bool         // whether they're being handed over
+}
+
+// New returns a closed breaker.
+func New(c Config) *Breaker {
+	if c.Failure == nil {
+		c.Failure = ftl.NotNil()
+	}
+	if c.Trip == nil {
+		threshold := c.Threshold
+		if threshold < 1 {
+			threshold = 1
+		}
+		c.Trip = func() ftl.Predicate {
+			return ftl.ConsecutiveFailures(threshold)
+		}
+	}
+	if c.Probes < 1 {
+		c.Probes = 1
+	}
+	return &Breaker{
+		c:    c,
+		trip: c.Trip(),
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.c.Cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// errPanicked is the outcome recorded for a call that panicked.
+var errPanicked = &ftl.PanicError{Value: "fbreak: call panicked"}
+
+// Wrap f so that calls to it go through the breaker. A call that
+// panics counts as a failure, and the panic carries on.
+func (b *Breaker) Wrap(f ftl.Tasklet) ftl.Tasklet {
+	return func(ctx context.Context) (err error) {
+		round, err := b.allow()
+		if err != nil {
+			return err
+		}
+		panicked := true
+		defer func() {
+			if panicked {
+				b.done(round, errPanicked)
+				return
+			}
+			b.done(round, err)
+		}()
+		err = f(ctx)
+		panicked = false
+		return err
+	}
+}
+
+// transition is a state change, to be handed to OnChange once the
+// lock is released.
+type transition struct{ from, to State }
+
+// notify hands the changes made so far to OnChange. Only one
+// goroutine does so at a time, in the order they were made; others
+// leave theirs to it.
+func (b *Breaker) notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.notifying {
+		return
+	}
+	b.notifying = true
+	defer func() { b.notifying = false }()
+
+	for len(b.changes) > 0 {
+		ts := b.changes
+		b.changes = nil
+		b.mu.Unlock()
+		for _, t := range ts {
+			b.c.OnChange(t.from, t.to)
+		}
+		b.mu.Lock()
+	}
+}
+
+// set the state of the breaker. Must be called with mu held.
+func (b *Breaker) set(to State) {
+	if b.state == to {
+		return
+	}
+	if b.c.OnChange != nil {
+		b.changes = append(b.changes, transition{b.state, to})
+	}
+	b.state = to
+
+	switch to {
+	case Closed:
+		b.trip = b.c.Trip()
+	case Open:
+		b.openedAt = time.Now()
+	case HalfOpen:
+		b.round++
+		b.probing, b.probed = 0, 0
+	}
+}
+
+// allow reports whether a call may go through. If it's a probe,
+// round is the round of being half-open it belongs to, or 0 if not.
+func (b *Breaker) allow() (round uint64, err error) {
+	defer b.notify()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && time.Since(b.openedAt) >= b.c.Cooldown {
+		b.set(HalfOpen)
+	}
+
+	switch b.state {
+	case Open:
+		return 0, ErrOpen
+	case HalfOpen:
+		if b.probing+b.probed >= b.c.Probes {
+			return 0, ErrOpen
+		}
+		b.probing++
+		return b.round, nil
+	}
+	return 0, nil
+}
+
+// done records the outcome of a call. Probes from an earlier round
+// of being half-open are ignored.
+func (b *Breaker) done(round uint64, err error) {
+	defer b.notify()
+
+	failed := err == errPanicked || err != nil && b.c.Failure(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case round != 0:
+		if b.state != HalfOpen || round != b.round {
+			return // stale
+		}
+		b.probing--
+		if failed {
+			b.set(Open)
+			return
+		}
+		if b.probed++; b.probed >= b.c.Probes {
+			b.set(Closed)
+		}
+
+	case b.state == Closed:
+		if !failed {
+			err = nil
+		}
+		if b.trip(err) {
+			b.set(Open)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package fbreak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nytopop/ftl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	var (
+		errX    = errors.New("x")
+		errSkip = errors.New("skip")
+		fail    = ftl.Tasklet(func(_ context.Context) error { return errX })
+		skip    = ftl.Tasklet(func(_ context.Context) error { return errSkip })
+		succ    = ftl.Tasklet(func(_ context.Context) error { return nil })
+	)
+
+	t.Run("Trips and recovers", func(t *testing.T) {
+		var changes []State
+		b := New(Config{
+			Failure:   ftl.Error(errSkip).Not(),
+			Threshold: 2,
+			Cooldown:  10 * time.Millisecond,
+			Probes:    2,
+			OnChange:  func(_, to State) { changes = append(changes, to) },
+		})
+
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+		assert.Equal(t, errSkip, b.Wrap(skip).Run()) // not a failure
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+		assert.Equal(t, Closed, b.State())
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+		assert.Equal(t, Open, b.State())
+		assert.Equal(t, ErrOpen, b.Wrap(succ).Run())
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, HalfOpen, b.State())
+		assert.NoError(t, b.Wrap(succ).Run())
+		assert.Equal(t, HalfOpen, b.State())
+		assert.NoError(t, b.Wrap(succ).Run())
+		assert.Equal(t, Closed, b.State())
+
+		assert.Equal(t, []State{Open, HalfOpen, Closed}, changes)
+	})
+
+	t.Run("Failed probe reopens", func(t *testing.T) {
+		b := New(Config{Threshold: 1, Cooldown: 10 * time.Millisecond})
+
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+		assert.Equal(t, Open, b.State())
+		assert.Equal(t, ErrOpen, b.Wrap(succ).Run())
+	})
+
+	t.Run("Probes are limited", func(t *testing.T) {
+		b := New(Config{Threshold: 1, Cooldown: 0, Probes: 1})
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+
+		probing, block := make(chan struct{}), make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- b.Wrap(func(_ context.Context) error {
+				close(probing)
+				<-block
+				return nil
+			}).Run()
+		}()
+
+		<-probing
+		assert.Equal(t, ErrOpen, b.Wrap(succ).Run())
+		close(block)
+		assert.NoError(t, <-done)
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("Panicking probe reopens", func(t *testing.T) {
+		b := New(Config{
+			Failure:  ftl.Error(errSkip).Not(),
+			Cooldown: 10 * time.Millisecond,
+		})
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+		time.Sleep(10 * time.Millisecond)
+
+		boom := b.Wrap(func(_ context.Context) error { panic("boom") })
+		assert.Panics(t, func() { _ = boom.Run() })
+		assert.Equal(t, Open, b.State())
+
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, b.Wrap(succ).Run())
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("Stale probes are ignored", func(t *testing.T) {
+		b := New(Config{Threshold: 1, Cooldown: 0, Probes: 2})
+		assert.Equal(t, errX, b.Wrap(fail).Run())
+
+		// a slow probe from the first round outlives it
+		probing, block := make(chan struct{}), make(chan struct{})
+		done := make(chan error)
+		slow := b.Wrap(func(_ context.Context) error {
+			probing <- struct{}{}
+			<-block
+			return nil
+		})
+		go func() { done <- slow.Run() }()
+		<-probing
+		assert.Equal(t, errX, b.Wrap(fail).Run()) // reopens
+
+		go func() { done <- slow.Run() }() // probe of the second round
+		<-probing
+		close(block)
+		assert.NoError(t, <-done)
+		assert.NoError(t, <-done)
+		assert.Equal(t, HalfOpen, b.State()) // only one probe counted
+	})
+
+	t.Run("Custom trip", func(t *testing.T) {
+		b := New(Config{
+			Trip:     func() ftl.Predicate { return ftl.ErrorRateAbove(0.5, 4) },
+			Cooldown: time.Hour,
+		})
+		for _, f := range []ftl.Tasklet{fail, fail, fail} {
+			_ = b.Wrap(f).Run()
+		}
+		assert.Equal(t, Closed, b.State()) // window isn't full yet
+		_ = b.Wrap(succ).Run()
+		assert.Equal(t, Open, b.State())
+	})
+}
@@ -0,0 +1,172 @@
+package ftl
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Fn is a function that might fail, producing a T when it
+// doesn't.
+type Fn[T any] func() (T, error)
+
+// Lift a Closure into an Fn that produces the zero value of T
+// on success.
+func Lift[T any](f Closure) Fn[T] {
+	return func() (T, error) {
+		var x T
+		return x, f()
+	}
+}
+
+func (f Fn[T]) Run() (T, error) {
+	return f()
+}
+
+// Closure discards the value produced by f.
+func (f Fn[T]) Closure() Closure {
+	return func() error {
+		_, err := f()
+		return err
+	}
+}
+
+// SeqFn runs fs in order, collecting the produced values.
+//
+// It is a function rather than a method on Fn, as Go doesn't allow
+// an Fn[T] method to produce an Fn[[]T].
+func SeqFn[T any](fs ...Fn[T]) Fn[[]T] {
+	return func() ([]T, error) {
+		xs := make([]T, 0, len(fs))
+		for i := range fs {
+			x, err := fs[i].step(context.Background(), at(StepSeq, i))()
+			if err != nil {
+				return nil, err
+			}
+			xs = append(xs, x)
+		}
+		return xs, nil
+	}
+}
+
+// ParFn runs fs concurrently. The produced values are in the same
+// order as the arguments.
+func ParFn[T any](fs ...Fn[T]) Fn[[]T] {
+	return func() ([]T, error) {
+		var (
+			eg errgroup.Group
+			bg = context.Background()
+			xs = make([]T, len(fs))
+		)
+		for i := range fs {
+			eg.Go(fs[i].step(bg, at(StepPar, i)).into(&xs[i]))
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+		return xs, nil
+	}
+}
+
+// ParAllFn is like ParFn, but waits for every function to return.
+// All of their errors are returned as a MultiError, and the values
+// produced by those that succeeded are kept.
+func ParAllFn[T any](fs ...Fn[T]) Fn[[]T] {
+	return func() ([]T, error) {
+		var (
+			xs = make([]T, len(fs))
+			cs = make([]Closure, len(fs))
+		)
+		for i := range fs {
+			cs[i] = fs[i].into(&xs[i])
+		}
+		return xs, goAll(context.Background(), lift(cs...))
+	}
+}
+
+// into returns a Closure that stores the value produced by f in x.
+func (f Fn[T]) into(x *T) Closure {
+	return func() error {
+		var err error
+		*x, err = f()
+		return err
+	}
+}
+
+func (f Fn[T]) cond(p Predicate, exit bool) Fn[T] {
+	return func() (T, error) {
+		for n := 1; ; n++ {
+			x, err := f.step(context.Background(), attempt(exit, n))()
+			if p(err) == exit {
+				return x, err
+			}
+		}
+	}
+}
+
+func (f Fn[T]) While(p Predicate) Fn[T] {
+	return Fn[T].cond(f, p, false)
+}
+
+func (f Fn[T]) Until(p Predicate) Fn[T] {
+	return Fn[T].cond(f, p, true)
+}
+
+// WhileEach is like While, but uses a fresh predicate from pf every
+// time it runs.
+func (f Fn[T]) WhileEach(pf PredicateFactory) Fn[T] {
+	return func() (T, error) {
+		return f.While(pf())()
+	}
+}
+
+// UntilEach is like Until, but uses a fresh predicate from pf every
+// time it runs.
+func (f Fn[T]) UntilEach(pf PredicateFactory) Fn[T] {
+	return func() (T, error) {
+		return f.Until(pf())()
+	}
+}
+
+// Ite runs f, then g if p holds for its error or z otherwise. The
+// value produced by f is discarded.
+func (f Fn[T]) Ite(p Predicate, g, z Fn[T]) Fn[T] {
+	return func() (T, error) {
+		bg := context.Background()
+		if _, err := f.step(bg, at(StepIte, 0))(); p(err) {
+			return g.step(bg, at(StepIte, 1))()
+		} else {
+			return z.step(bg, at(StepIte, 2))()
+		}
+	}
+}
+
+func (f Fn[T]) Mu(mu sync.Locker) Fn[T] {
+	return func() (T, error) {
+		mu.Lock()
+		x, err := f()
+		mu.Unlock()
+		return x, err
+	}
+}
+
+func (f Fn[T]) Once() Fn[T] {
+	var (
+		once sync.Once
+		x    T
+		err  error
+	)
+	return func() (T, error) {
+		once.Do(func() { x, err = f() })
+		return x, err
+	}
+}
+
+// Recover converts a panic in f into a *PanicError.
+func (f Fn[T]) Recover() Fn[T] {
+	return func() (x T, err error) {
+		defer recovers(&err)
+		return f()
+	}
+}
@@ -0,0 +1,92 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ret[T any](x T, d time.Duration) Fn[T] {
+	return func() (T, error) {
+		time.Sleep(d)
+		return x, nil
+	}
+}
+
+func TestFn(t *testing.T) {
+	t.Run("Par keeps input order", func(t *testing.T) {
+		xs, err := ParFn(
+			ret(1, 30*time.Millisecond),
+			ret(2, 20*time.Millisecond),
+			ret(3, 10*time.Millisecond),
+			ret(4, 0),
+		).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4}, xs)
+	})
+
+	t.Run("Seq short circuits", func(t *testing.T) {
+		var i int
+		errX := errors.New("x")
+		xs, err := SeqFn(
+			ret(1, 0),
+			func() (int, error) { i++; return 0, errX },
+			func() (int, error) { i++; return 3, nil },
+		).Run()
+		assert.Equal(t, errX, err)
+		assert.Nil(t, xs)
+		assert.Equal(t, 1, i)
+	})
+
+	t.Run("Until keeps last value", func(t *testing.T) {
+		var i int
+		x, err := Fn[int](func() (int, error) {
+			i++
+			return i, nil
+		}).Until(TriesEq(3)).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, x)
+	})
+
+	t.Run("Lift", func(t *testing.T) {
+		var i int
+		x, err := Lift[string](succ(&i)).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, "", x)
+		assert.Equal(t, 1, i)
+
+		_, err = Lift[string](fail(&i)).Run()
+		assert.Error(t, err)
+		assert.Equal(t, 2, i)
+	})
+}
+
+func TestTask(t *testing.T) {
+	t.Run("Par cancels on failure", func(t *testing.T) {
+		errX := errors.New("x")
+		xs, err := ParTask[int](
+			func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			},
+			func(_ context.Context) (int, error) {
+				return 0, errX
+			},
+		).Run()
+		assert.Equal(t, errX, err)
+		assert.Nil(t, xs)
+	})
+
+	t.Run("LiftTask", func(t *testing.T) {
+		_, err := LiftTask[int](retErr).Run()
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = LiftTask[int](retErr)(ctx)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
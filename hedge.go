@@ -0,0 +1,107 @@
+package ftl
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// HedgeStats counts what a hedged Tasklet has been up to.
+type HedgeStats struct {
+	calls  uint64
+	hedges uint64
+	wins   uint64
+}
+
+// Calls returns the number of times the hedged tasklet was run.
+func (s *HedgeStats) Calls() uint64 {
+	return atomic.LoadUint64(&s.calls)
+}
+
+// Hedges returns the number of extra attempts that were launched,
+// across all calls.
+func (s *HedgeStats) Hedges() uint64 {
+	return atomic.LoadUint64(&s.hedges)
+}
+
+// Wins returns the number of calls that succeeded by way of an extra
+// attempt, rather than the first.
+func (s *HedgeStats) Wins() uint64 {
+	return atomic.LoadUint64(&s.wins)
+}
+
+// Hedge runs f, and launches another concurrent attempt every time
+// delay passes without any of them succeeding, up to maxAttempts in
+// total. The first success is returned and the remaining attempts
+// are interrupted. A failed attempt isn't retried: if every attempt
+// launched so far fails, their errors are returned as a MultiError.
+//
+// The returned stats are shared by every call of the hedged tasklet.
+func (f Tasklet) Hedge(delay time.Duration, maxAttempts int,
+) (Tasklet, *HedgeStats) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	stats := new(HedgeStats)
+
+	return func(ctx context.Context) error {
+		type result struct {
+			i   int
+			err error
+		}
+
+		atomic.AddUint64(&stats.calls, 1)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var (
+			results  = make(chan result, maxAttempts)
+			launched int
+			pending  int
+			errs     = make(MultiError, 0, maxAttempts)
+			timer    = time.NewTimer(delay)
+		)
+		defer timer.Stop()
+
+		launch := func() {
+			if launched == maxAttempts || ctx.Err() != nil {
+				return
+			}
+			if launched > 0 {
+				atomic.AddUint64(&stats.hedges, 1)
+			}
+			go func(i int) {
+				results <- result{i, f.step(ctx, at(StepHedge, i))()}
+			}(launched)
+			launched++
+			pending++
+		}
+
+		for launch(); pending > 0; {
+			select {
+			case <-timer.C:
+				launch()
+				if launched < maxAttempts {
+					timer.Reset(delay) // C was just drained
+				}
+
+			case r := <-results:
+				pending--
+				if r.err == nil {
+					if r.i > 0 {
+						atomic.AddUint64(&stats.wins, 1)
+					}
+					cancel() // interrupt the other attempts
+					for ; pending > 0; pending-- {
+						<-results
+					}
+					return nil
+				}
+				errs = append(errs, BranchError{r.i, r.err})
+			}
+		}
+
+		return errs.sorted()
+	}, stats
+}
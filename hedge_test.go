@@ -0,0 +1,56 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedge(t *testing.T) {
+	t.Run("Hedge wins", func(t *testing.T) {
+		var n int32
+		f, stats := Tasklet(func(ctx context.Context) error {
+			if atomic.AddInt32(&n, 1) == 1 {
+				return sleeper(time.Second)(ctx) // stuck in the tail
+			}
+			return nil
+		}).Hedge(5*time.Millisecond, 3)
+
+		start := time.Now()
+		assert.NoError(t, f.Run())
+		assert.True(t, time.Since(start) < time.Second)
+		assert.Equal(t, uint64(1), stats.Calls())
+		assert.Equal(t, uint64(1), stats.Hedges())
+		assert.Equal(t, uint64(1), stats.Wins())
+	})
+
+	t.Run("Fast path", func(t *testing.T) {
+		f, stats := sleeper(0).Hedge(time.Second, 3)
+		assert.NoError(t, f.Run())
+		assert.Equal(t, uint64(0), stats.Hedges())
+	})
+
+	t.Run("All fail", func(t *testing.T) {
+		errX := errors.New("x")
+		f, stats := failAfter(20*time.Millisecond, errX).Hedge(5*time.Millisecond, 3)
+		err := f.Run()
+		assert.Len(t, err, 3)
+		assert.True(t, errors.Is(err, errX))
+		assert.Equal(t, uint64(2), stats.Hedges())
+	})
+
+	t.Run("Failure isn't retried", func(t *testing.T) {
+		errX := errors.New("x")
+		f, stats := failAfter(0, errX).Hedge(time.Second, 3)
+
+		start := time.Now()
+		err := f.Run()
+		assert.True(t, time.Since(start) < time.Second)
+		assert.Len(t, err, 1)
+		assert.Equal(t, uint64(0), stats.Hedges())
+	})
+}
@@ -0,0 +1,322 @@
+package ftl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StepKind is the kind of combinator a Step belongs to.
+type StepKind int
+
+const (
+	StepSeq StepKind = iota
+	StepPar
+	StepWhile
+	StepUntil
+	StepIte
+	StepRace
+	StepQuorum
+	StepOrElse
+	StepFinally
+	StepHedge
+)
+
+func (k StepKind) String() string {
+	switch k {
+	case StepSeq:
+		return "seq"
+	case StepPar:
+		return "par"
+	case StepWhile:
+		return "while"
+	case StepUntil:
+		return "until"
+	case StepIte:
+		return "ite"
+	case StepRace:
+		return "race"
+	case StepQuorum:
+		return "quorum"
+	case StepOrElse:
+		return "orelse"
+	case StepFinally:
+		return "finally"
+	case StepHedge:
+		return "hedge"
+	}
+	return fmt.Sprintf("StepKind(%d)", int(k))
+}
+
+// Step identifies one run of a function within a composition.
+type Step struct {
+	Kind StepKind
+
+	// Path leads to the composition the step is part of, through the
+	// steps and named functions enclosing it, as in the path of a
+	// *StepError, such as "deploy/seq[2]". It's empty at the top, and
+	// within Closures, Statelets and Fns, which have no context to
+	// carry it.
+	Path string
+
+	// Index is the position of the function within a Seq, Par,
+	// Race, Quorum or OrElse, or the attempt within a Hedge starting
+	// at 0. Within an Ite, it's 0 for the condition, 1 for the
+	// function run if it holds and 2 for the one run if it doesn't.
+	// Within a Finally, it's 0 for the function and 1 for the one
+	// run after it.
+	Index int
+
+	// Attempt counts the runs of the function within a While or
+	// Until, starting at 1. It's always 1 for other kinds.
+	Attempt int
+}
+
+// String returns the segment s adds to a path, such as "seq[2]".
+func (s Step) String() string {
+	if s.Kind == StepWhile || s.Kind == StepUntil {
+		return fmt.Sprintf("%v#%d", s.Kind, s.Attempt)
+	}
+	return fmt.Sprintf("%v[%d]", s.Kind, s.Index)
+}
+
+// within adds s to the path of any *StepError in err. Within a
+// Named function, other errors are given a path starting at s, so
+// that it leads to the step that failed even if that isn't named.
+func (s Step) within(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case hasStep(err):
+		return within(s.String(), err)
+	case pathOf(ctx).named():
+		return &StepError{Path: s.String(), Err: err}
+	}
+	return err
+}
+
+// Interceptor is called whenever a step of a composition starts.
+// The function it returns, if not nil, is called when the step ends
+// with how long it took and its error. Whatever error that returns
+// replaces the error of the step, so return err to leave it be.
+//
+// Interceptors may be called concurrently.
+type Interceptor func(s Step) func(d time.Duration, err error) error
+
+// global holds the interceptors installed with Intercept.
+var global struct {
+	mu sync.Mutex
+	is atomic.Pointer[[]*Interceptor]
+}
+
+// Intercept installs i for every composition in the process, until
+// remove is called.
+func Intercept(i Interceptor) (remove func()) {
+	p := &i
+
+	global.mu.Lock()
+	defer global.mu.Unlock()
+	var is []*Interceptor
+	if old := global.is.Load(); old != nil {
+		is = append(is, *old...)
+	}
+	is = append(is, p)
+	global.is.Store(&is)
+
+	return func() {
+		global.mu.Lock()
+		defer global.mu.Unlock()
+		var is []*Interceptor
+		for _, q := range *global.is.Load() {
+			if q != p {
+				is = append(is, q)
+			}
+		}
+		global.is.Store(&is)
+	}
+}
+
+type interceptKey struct{}
+
+// Intercept installs i for the steps of compositions run within f.
+// Interceptors installed this way run after global ones, outermost
+// first.
+//
+// Closures, Statelets and Fns have no context to carry interceptors,
+// so only global ones see the steps of their compositions.
+func (f Tasklet) Intercept(i Interceptor) Tasklet {
+	return func(ctx context.Context) error {
+		is, _ := ctx.Value(interceptKey{}).([]Interceptor)
+		is = append(is[:len(is):len(is)], i)
+		return f(context.WithValue(ctx, interceptKey{}, is))
+	}
+}
+
+// Intercept is like Tasklet.Intercept.
+func (f Routine) Intercept(i Interceptor) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Ap2(state).Intercept(i)(ctx)
+	}
+}
+
+// Intercept is like Tasklet.Intercept.
+func (f Task[T]) Intercept(i Interceptor) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		var x T
+		err := f.into(&x).Intercept(i)(ctx)
+		return x, err
+	}
+}
+
+type pathKey struct{}
+
+// pathNode is a step or named function that a context is within.
+type pathNode struct {
+	parent  *pathNode
+	step    Step
+	name    string
+	isName  bool // whether it's a named function rather than a step
+	inNamed bool // whether it or any parent is a named function
+}
+
+// pathOf returns what ctx is innermost within, if anything.
+func pathOf(ctx context.Context) *pathNode {
+	n, _ := ctx.Value(pathKey{}).(*pathNode)
+	return n
+}
+
+// enter returns ctx within step s.
+func enter(ctx context.Context, s Step) context.Context {
+	p := pathOf(ctx)
+	return context.WithValue(ctx, pathKey{}, &pathNode{
+		parent:  p,
+		step:    s,
+		inNamed: p.named(),
+	})
+}
+
+// naming returns ctx within the function named name.
+func naming(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, pathKey{}, &pathNode{
+		parent:  pathOf(ctx),
+		name:    name,
+		isName:  true,
+		inNamed: true,
+	})
+}
+
+// named reports whether n is within a named function.
+func (n *pathNode) named() bool {
+	return n != nil && n.inNamed
+}
+
+func (n *pathNode) String() string {
+	if n == nil {
+		return ""
+	}
+	seg := n.name
+	if !n.isName {
+		seg = n.step.String()
+	}
+	if n.parent == nil {
+		return seg
+	}
+	return n.parent.String() + "/" + seg
+}
+
+// interceptors returns the interceptors that apply within ctx.
+func interceptors(ctx context.Context) []Interceptor {
+	var is []Interceptor
+	if gs := global.is.Load(); gs != nil {
+		for _, g := range *gs {
+			is = append(is, *g)
+		}
+	}
+	if ls, ok := ctx.Value(interceptKey{}).([]Interceptor); ok {
+		is = append(is, ls...)
+	}
+	return is
+}
+
+// step runs f as step s of a composition, reporting it to the
+// interceptors that apply within ctx. If f fails, s is added to the
+// path of its error as with Step.within. Panics in f are recovered if ctx says so.
+func step(ctx context.Context, s Step, f Closure) error {
+	if recovered(ctx) {
+		f = f.Recover()
+	}
+
+	is := interceptors(ctx)
+	if len(is) == 0 {
+		return s.within(ctx, f())
+	}
+
+	s.Path = pathOf(ctx).String()
+	ends := make([]func(time.Duration, error) error, 0, len(is))
+	for _, i := range is {
+		if end := i(s); end != nil {
+			ends = append(ends, end)
+		}
+	}
+	start := time.Now()
+	err := s.within(ctx, f())
+	d := time.Since(start)
+	for j := len(ends) - 1; j >= 0; j-- {
+		err = ends[j](d, err)
+	}
+	return err
+}
+
+// at is step i of a Seq, Par or Ite.
+func at(k StepKind, i int) Step {
+	return Step{Kind: k, Index: i, Attempt: 1}
+}
+
+// attempt is the nth run within a While or Until.
+func attempt(exit bool, n int) Step {
+	if exit {
+		return Step{Kind: StepUntil, Attempt: n}
+	}
+	return Step{Kind: StepWhile, Attempt: n}
+}
+
+// step returns a Closure that runs f as step s, as with step.
+func (f Closure) step(ctx context.Context, s Step) Closure {
+	return func() error {
+		return step(ctx, s, f)
+	}
+}
+
+// step is like Closure.step, but runs f within s, so that its own
+// steps know the path to them.
+func (f Tasklet) step(ctx context.Context, s Step) Closure {
+	return func() error {
+		return step(ctx, s, f.Ap(enter(ctx, s)))
+	}
+}
+
+// step is like Closure.step.
+func (f Fn[T]) step(ctx context.Context, s Step) Fn[T] {
+	return func() (T, error) {
+		var x T
+		err := step(ctx, s, f.into(&x))
+		return x, err
+	}
+}
+
+// step is like Tasklet.step.
+func (f Task[T]) step(ctx context.Context, s Step) Fn[T] {
+	return f.Ap(enter(ctx, s)).step(ctx, s)
+}
+
+// lift returns fs as Tasklets that ignore their context.
+func lift(fs ...Closure) []Tasklet {
+	ts := make([]Tasklet, len(fs))
+	for i := range fs {
+		f := fs[i]
+		ts[i] = func(context.Context) error { return f() }
+	}
+	return ts
+}
@@ -0,0 +1,147 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is an Interceptor that records the steps it sees.
+type recorder struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (r *recorder) intercept(s Step) func(time.Duration, error) error {
+	return func(_ time.Duration, err error) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err != nil {
+			r.steps = append(r.steps, s.String()+"!")
+		} else {
+			r.steps = append(r.steps, s.String())
+		}
+		return err
+	}
+}
+
+func TestIntercept(t *testing.T) {
+	errX := errors.New("x")
+
+	t.Run("Global", func(t *testing.T) {
+		var (
+			r      recorder
+			i      int
+			remove = Intercept(r.intercept)
+		)
+		f := Closure(succ(&i)).Seq(
+			Closure(fail(&i)).UntilEach(func() Predicate {
+				return TriesEq(2)
+			}),
+			succ(&i),
+		)
+		assert.Error(t, f())
+		assert.Equal(t, []string{
+			"seq[0]", "until#1!", "until#2!", "seq[1]!",
+		}, r.steps)
+
+		remove()
+		r.steps = nil
+		assert.Error(t, f())
+		assert.Empty(t, r.steps)
+	})
+
+	t.Run("Per composition", func(t *testing.T) {
+		var r recorder
+		f := Tasklet(func(context.Context) error { return nil }).Par(
+			func(context.Context) error { return nil },
+		)
+		g := f.Intercept(r.intercept)
+
+		assert.NoError(t, f.Run())
+		assert.Empty(t, r.steps)
+
+		assert.NoError(t, g.Run())
+		assert.ElementsMatch(t, []string{"par[0]", "par[1]"}, r.steps)
+	})
+
+	t.Run("Paths", func(t *testing.T) {
+		var (
+			mu    sync.Mutex
+			paths []string
+		)
+		ok := Tasklet(func(context.Context) error { return nil })
+		f := ok.Seq(ok.Seq(ok), ok.Par(ok)).Named("deploy").Intercept(
+			func(s Step) func(time.Duration, error) error {
+				mu.Lock()
+				defer mu.Unlock()
+				paths = append(paths, s.Path+" "+s.String())
+				return nil
+			},
+		)
+
+		assert.NoError(t, f.Run())
+		assert.ElementsMatch(t, []string{
+			"deploy seq[0]",
+			"deploy seq[1]", "deploy/seq[1] seq[0]", "deploy/seq[1] seq[1]",
+			"deploy seq[2]", "deploy/seq[2] par[0]", "deploy/seq[2] par[1]",
+		}, paths)
+	})
+
+	t.Run("Task", func(t *testing.T) {
+		var r recorder
+		one := Task[int](func(context.Context) (int, error) { return 1, nil })
+		f := SeqTask(one, one).Intercept(r.intercept)
+
+		xs, err := f.Run()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 1}, xs)
+		assert.Equal(t, []string{"seq[0]", "seq[1]"}, r.steps)
+	})
+
+	t.Run("Ite", func(t *testing.T) {
+		var r recorder
+		f := Routine(func(context.Context, StateLoader) error { return errX }).Ite(
+			NotNil(),
+			func(context.Context, StateLoader) error { return nil },
+			func(context.Context, StateLoader) error { return errX },
+		).Intercept(r.intercept)
+
+		assert.NoError(t, f(context.Background(), new(State)))
+		assert.Equal(t, []string{"ite[0]!", "ite[1]"}, r.steps)
+	})
+
+	t.Run("Duration and error", func(t *testing.T) {
+		var d time.Duration
+		f := Tasklet(sleeper(20 * time.Millisecond)).Seq().Intercept(
+			func(s Step) func(time.Duration, error) error {
+				return func(sd time.Duration, err error) error {
+					d = sd
+					return errX // injected
+				}
+			},
+		)
+		assert.Equal(t, errX, f.Run())
+		assert.True(t, d >= 20*time.Millisecond)
+	})
+
+	t.Run("Nested outermost first", func(t *testing.T) {
+		var order []string
+		named := func(name string) Interceptor {
+			return func(Step) func(time.Duration, error) error {
+				order = append(order, name)
+				return nil
+			}
+		}
+		f := Tasklet(func(context.Context) error { return nil }).Seq().
+			Intercept(named("inner")).
+			Intercept(named("outer"))
+
+		assert.NoError(t, f.Run())
+		assert.Equal(t, []string{"outer", "inner"}, order)
+	})
+}
@@ -0,0 +1,133 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned in place of calling a function whose
+// rate limit has been exceeded, when it's not allowed to wait.
+var ErrRateLimited = errors.New("ftl: rate limited")
+
+// Limiter is a token bucket rate limiter. It holds up to burst
+// tokens, and refills at rate tokens per second. Every call through
+// a limiter takes a token.
+//
+// A limiter can be shared by any number of functions, which are then
+// limited together.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a full limiter. With a rate <= 0, it never
+// refills: once its burst tokens are taken, every call through it
+// fails with ErrRateLimited.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill the bucket. Must be called with mu held.
+func (l *Limiter) refill() {
+	t := time.Now()
+	l.tokens += t.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = t
+}
+
+// Allow takes a token if one is available.
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait takes a token, waiting until one is available. If ctx is
+// cancelled first, it gives up and returns ctx.Err(). If one will
+// never be available, it returns ErrRateLimited right away.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill()
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return ErrRateLimited
+	}
+
+	// reserve the token in advance, and wait for it to arrive
+	l.tokens--
+	d := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if !wait(ctx, d) {
+		l.mu.Lock()
+		l.tokens++ // hand the reservation back
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+	return nil
+}
+
+// RateLimit waits for a token from l before every call of f.
+func (f Tasklet) RateLimit(l *Limiter) Tasklet {
+	return func(ctx context.Context) error {
+		if err := l.Wait(ctx); err != nil {
+			return err
+		}
+		return f(ctx)
+	}
+}
+
+// TryRateLimit is like RateLimit, but returns ErrRateLimited
+// instead of waiting if l has no tokens.
+func (f Tasklet) TryRateLimit(l *Limiter) Tasklet {
+	return func(ctx context.Context) error {
+		if !l.Allow() {
+			return ErrRateLimited
+		}
+		return f(ctx)
+	}
+}
+
+// RateLimit waits for a token from l before every call of f.
+func (f Closure) RateLimit(l *Limiter) Closure {
+	return func() error {
+		if err := l.Wait(context.Background()); err != nil {
+			return err
+		}
+		return f()
+	}
+}
+
+// TryRateLimit is like RateLimit, but returns ErrRateLimited
+// instead of waiting if l has no tokens.
+func (f Closure) TryRateLimit(l *Limiter) Closure {
+	return func() error {
+		if !l.Allow() {
+			return ErrRateLimited
+		}
+		return f()
+	}
+}
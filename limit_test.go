@@ -0,0 +1,58 @@
+package ftl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("Shared", func(t *testing.T) {
+		var i, j int
+		l := NewLimiter(100, 2)
+		f, g := Closure(succ(&i)).RateLimit(l), Closure(succ(&j)).RateLimit(l)
+
+		start := time.Now()
+		for n := 0; n < 3; n++ {
+			assert.NoError(t, f())
+			assert.NoError(t, g())
+		}
+		// 2 for free, then 4 more at 100/s
+		assert.True(t, time.Since(start) >= 30*time.Millisecond)
+		assert.Equal(t, 3, i)
+		assert.Equal(t, 3, j)
+	})
+
+	t.Run("Wait cancels", func(t *testing.T) {
+		l := NewLimiter(0.001, 1)
+		assert.NoError(t, retErr.RateLimit(l).Run())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, retErr.RateLimit(l)(ctx))
+	})
+
+	t.Run("Never refills", func(t *testing.T) {
+		var i int
+		l := NewLimiter(0, 1)
+		f := Closure(succ(&i)).RateLimit(l)
+		assert.NoError(t, f())
+		assert.Equal(t, ErrRateLimited, f())
+		assert.Equal(t, ErrRateLimited, l.Wait(context.Background()))
+		assert.Equal(t, 1, i)
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		var i, j int
+		l := NewLimiter(0, 1)
+		f := Closure(succ(&i)).TryRateLimit(l).
+			Ite(Error(ErrRateLimited), succ(&j), Fail(nil))
+
+		assert.NoError(t, f())
+		assert.NoError(t, f())
+		assert.Equal(t, 1, i)
+		assert.Equal(t, 1, j)
+	})
+}
@@ -0,0 +1,207 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSharedKey is returned by a Task that's Shared under a key
+// whose execution produced something other than a T, because a
+// function of another type was Shared under the same key.
+var ErrSharedKey = errors.New("ftl: shared key used with another type")
+
+// flight is an execution of a Shared function.
+type flight struct {
+	done    chan struct{}
+	x       interface{}
+	err     error
+	waiters int // guarded by flights.mu
+	cancel  context.CancelFunc
+}
+
+// flights holds the executions of Shared functions by key.
+var flights = struct {
+	mu sync.Mutex
+	m  map[string]*flight
+}{m: make(map[string]*flight)}
+
+// share calls f under key, or joins the call of f already running
+// under it. If ctx is cancelled first, it returns a nil value and
+// ctx.Err(). The call is cancelled once all callers have stopped
+// waiting for it, and later ones make a new call.
+func share(ctx context.Context, key string,
+	f func(context.Context) (interface{}, error),
+) (interface{}, error) {
+	flights.mu.Lock()
+	fl, ok := flights.m[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		fl = &flight{done: make(chan struct{}), cancel: cancel}
+		flights.m[key] = fl
+		go func() {
+			fl.x, fl.err = f(fctx)
+			flights.mu.Lock()
+			fl.forget(key)
+			flights.mu.Unlock()
+			close(fl.done)
+		}()
+	}
+	fl.waiters++
+	flights.mu.Unlock()
+
+	select {
+	case <-fl.done:
+		return fl.x, fl.err
+	case <-ctx.Done():
+		flights.mu.Lock()
+		if fl.waiters--; fl.waiters == 0 {
+			fl.forget(key)
+		}
+		flights.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// forget cancels fl and removes it from flights, if it's still there.
+// flights.mu must be held.
+func (fl *flight) forget(key string) {
+	fl.cancel()
+	if flights.m[key] == fl {
+		delete(flights.m, key)
+	}
+}
+
+// shared boxes the value produced by a shared Task, so that it can
+// be told apart from those of other types.
+type shared[T any] struct{ x T }
+
+// Shared makes concurrent calls of f share a single execution. Keys
+// are global to the process: calls of any functions Shared under the
+// same key share an execution.
+//
+// The execution is given a context that carries the values of the
+// call that started it, but isn't cancelled along with it. Each call
+// stops waiting for it if its own context is cancelled, and once
+// none are left waiting, the execution is cancelled and the next call
+// starts a new one.
+func (f Tasklet) Shared(key string) Tasklet {
+	return func(ctx context.Context) error {
+		_, err := share(ctx, key, func(ctx context.Context) (interface{}, error) {
+			return shared[struct{}]{}, f(ctx)
+		})
+		return err
+	}
+}
+
+// Shared is like Tasklet.Shared. Every call gets the same T. If the
+// shared execution was of a function that doesn't produce a T, the
+// call fails with ErrSharedKey.
+func (f Task[T]) Shared(key string) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		v, err := share(ctx, key, func(ctx context.Context) (interface{}, error) {
+			x, err := f(ctx)
+			return shared[T]{x}, err
+		})
+		if v == nil { // gave up waiting
+			var x T
+			return x, err
+		}
+		s, ok := v.(shared[T])
+		if !ok {
+			return s.x, fmt.Errorf("%w: %q", ErrSharedKey, key)
+		}
+		return s.x, err
+	}
+}
+
+// memo remembers a value until it expires.
+type memo[T any] struct {
+	mu    sync.Mutex
+	x     T
+	until time.Time
+}
+
+func (m *memo[T]) get() (x T, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.x, time.Now().Before(m.until)
+}
+
+func (m *memo[T]) put(x T, ttl time.Duration) {
+	m.mu.Lock()
+	m.x, m.until = x, time.Now().Add(ttl)
+	m.mu.Unlock()
+}
+
+// Memo skips calling f for ttl after it succeeds. Unlike Once,
+// failures aren't remembered.
+//
+// Calls that miss at the same time all call f; use Shared as well to
+// have them share one execution.
+func (f Closure) Memo(ttl time.Duration) Closure {
+	var m memo[struct{}]
+	return func() error {
+		if _, ok := m.get(); ok {
+			return nil
+		}
+		if err := f(); err != nil {
+			return err
+		}
+		m.put(struct{}{}, ttl)
+		return nil
+	}
+}
+
+// Memo is like Closure.Memo.
+func (f Tasklet) Memo(ttl time.Duration) Tasklet {
+	var m memo[struct{}]
+	return func(ctx context.Context) error {
+		if _, ok := m.get(); ok {
+			return nil
+		}
+		if err := f(ctx); err != nil {
+			return err
+		}
+		m.put(struct{}{}, ttl)
+		return nil
+	}
+}
+
+// Memo remembers the value produced by f for ttl after it succeeds.
+// Unlike Once, failures aren't remembered.
+//
+// Calls that miss at the same time all call f.
+func (f Fn[T]) Memo(ttl time.Duration) Fn[T] {
+	var m memo[T]
+	return func() (T, error) {
+		if x, ok := m.get(); ok {
+			return x, nil
+		}
+		x, err := f()
+		if err != nil {
+			return x, err
+		}
+		m.put(x, ttl)
+		return x, nil
+	}
+}
+
+// Memo is like Fn.Memo. Use Shared as well to have calls that miss
+// at the same time share one execution.
+func (f Task[T]) Memo(ttl time.Duration) Task[T] {
+	var m memo[T]
+	return func(ctx context.Context) (T, error) {
+		if x, ok := m.get(); ok {
+			return x, nil
+		}
+		x, err := f(ctx)
+		if err != nil {
+			return x, err
+		}
+		m.put(x, ttl)
+		return x, nil
+	}
+}
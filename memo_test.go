@@ -0,0 +1,140 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShared(t *testing.T) {
+	var runs int32
+	f := Task[int](func(ctx context.Context) (int, error) {
+		time.Sleep(20 * time.Millisecond)
+		return int(atomic.AddInt32(&runs, 1)), nil
+	}).Shared(t.Name())
+
+	var (
+		wg sync.WaitGroup
+		xs = make([]int, 8)
+	)
+	for i := range xs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			xs[i], _ = f.Run()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+	for _, x := range xs {
+		assert.Equal(t, 1, x)
+	}
+}
+
+func TestSharedKeyTypes(t *testing.T) {
+	block := make(chan struct{})
+	s := Task[string](func(ctx context.Context) (string, error) {
+		<-block
+		return "x", nil
+	}).Shared(t.Name())
+	i := Task[int](func(ctx context.Context) (int, error) {
+		return 1, nil
+	}).Shared(t.Name())
+
+	done := make(chan string)
+	go func() {
+		x, _ := s.Run()
+		done <- x
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(block)
+	}()
+	_, err := i.Run()
+	assert.True(t, errors.Is(err, ErrSharedKey))
+	assert.Equal(t, "x", <-done)
+}
+
+func TestSharedDetached(t *testing.T) {
+	f := Tasklet(sleeper(30 * time.Millisecond)).Shared(t.Name())
+
+	first, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	errs := make(chan error)
+	go func() { errs <- f(first) }()
+	time.Sleep(5 * time.Millisecond)
+
+	assert.NoError(t, f.Run()) // unaffected by the first caller giving up
+	assert.Equal(t, context.DeadlineExceeded, <-errs)
+}
+
+func TestSharedAbandoned(t *testing.T) {
+	var runs int32
+	f := Tasklet(func(ctx context.Context) error {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			<-ctx.Done() // long poll
+			return ctx.Err()
+		}
+		return nil
+	}).Shared(t.Name())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, f(ctx))
+
+	// the abandoned execution was cancelled and forgotten
+	assert.NoError(t, f.Run())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
+
+func TestMemo(t *testing.T) {
+	errX := errors.New("x")
+
+	t.Run("Successes", func(t *testing.T) {
+		var i int
+		f := Closure(succ(&i)).Memo(20 * time.Millisecond)
+		assert.NoError(t, f())
+		assert.NoError(t, f())
+		assert.Equal(t, 1, i)
+
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, f())
+		assert.Equal(t, 2, i)
+	})
+
+	t.Run("Not failures", func(t *testing.T) {
+		var i int
+		f := Tasklet(func(_ context.Context) error {
+			return fail(&i)()
+		}).Memo(time.Hour)
+		assert.Error(t, f.Run())
+		assert.Error(t, f.Run())
+		assert.Equal(t, 2, i)
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		var i int
+		f := Fn[int](func() (int, error) {
+			i++
+			if i == 1 {
+				return 0, errX
+			}
+			return i, nil
+		}).Memo(time.Hour)
+
+		_, err := f()
+		assert.Equal(t, errX, err)
+		x, _ := f()
+		assert.Equal(t, 2, x)
+		x, _ = f()
+		assert.Equal(t, 2, x)
+	})
+}
@@ -0,0 +1,66 @@
+package ftl
+
+import "context"
+
+// Named names f, so that its errors are returned as a *StepError
+// with a path to it. Compositions that f is part of add themselves
+// to the path on the way out.
+//
+// For a Tasklet, Routine or Task, the path also leads to the step
+// within f that failed, even if that isn't named itself. Other types
+// have no context to carry that, so their paths only lead as far as
+// the innermost named function.
+func (f Closure) Named(name string) Closure {
+	return func() error {
+		return named(name, f())
+	}
+}
+
+// named returns err as a *StepError under name, or within it if
+// it has one already.
+func named(name string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case hasStep(err):
+		return within(name, err)
+	}
+	return &StepError{Path: name, Err: err}
+}
+
+// Named is like Closure.Named.
+func (f Tasklet) Named(name string) Tasklet {
+	return func(ctx context.Context) error {
+		return named(name, f(naming(ctx, name)))
+	}
+}
+
+// Named is like Closure.Named.
+func (f Statelet) Named(name string) Statelet {
+	return func(state StateLoader) error {
+		return named(name, f(state))
+	}
+}
+
+// Named is like Closure.Named.
+func (f Routine) Named(name string) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return named(name, f(naming(ctx, name), state))
+	}
+}
+
+// Named is like Closure.Named.
+func (f Fn[T]) Named(name string) Fn[T] {
+	return func() (T, error) {
+		x, err := f()
+		return x, named(name, err)
+	}
+}
+
+// Named is like Closure.Named.
+func (f Task[T]) Named(name string) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		x, err := f(naming(ctx, name))
+		return x, named(name, err)
+	}
+}
@@ -0,0 +1,114 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamed(t *testing.T) {
+	errX := errors.New("x")
+	ok := func(context.Context, StateLoader) error { return nil }
+
+	t.Run("Path", func(t *testing.T) {
+		upload := Routine(func(context.Context, StateLoader) error {
+			return errX
+		}).Named("upload")
+
+		deploy := Routine(ok).Seq(
+			ok,
+			Routine(ok).Par(upload),
+		).Named("deploy")
+
+		err := deploy(context.Background(), new(State))
+		var se *StepError
+		if assert.True(t, errors.As(err, &se)) {
+			assert.Equal(t, "deploy/seq[2]/par[1]/upload", se.Path)
+		}
+		assert.Equal(t, "deploy/seq[2]/par[1]/upload: x", err.Error())
+		assert.True(t, errors.Is(err, errX))
+		assert.Equal(t, errX, pkgerrors.Cause(err))
+		assert.True(t, Error(errX)(err))
+	})
+
+	t.Run("Unnamed leaf", func(t *testing.T) {
+		bad := Routine(func(context.Context, StateLoader) error {
+			return errX
+		})
+		deploy := Routine(ok).Seq(ok, Routine(ok).Par(bad)).Named("deploy")
+
+		err := deploy(context.Background(), new(State))
+		assert.Equal(t, "deploy/seq[2]/par[1]: x", err.Error())
+		assert.True(t, errors.Is(err, errX))
+
+		// unnamed compositions leave errors be
+		assert.Equal(t, errX, Routine(ok).Seq(bad)(context.Background(), new(State)))
+	})
+
+	t.Run("Through other combinators", func(t *testing.T) {
+		ok := Tasklet(func(context.Context) error { return nil })
+		up := Tasklet(func(context.Context) error { return errX }).Named("upload")
+		path := func(err error) string {
+			var se *StepError
+			if !errors.As(err, &se) {
+				return ""
+			}
+			return se.Path
+		}
+
+		err := ok.Seq(ok.ParAll(up)).Named("deploy").Run()
+		assert.Equal(t, "deploy/seq[1]/par[1]/upload", path(err))
+		assert.Equal(t, "ftl: 1 errors: [1] deploy/seq[1]/par[1]/upload: x", err.Error())
+
+		assert.Equal(t, "par[1]/upload", path(ok.ParN(1, up).Run()))
+		assert.Equal(t, "race[0]/upload", path(up.Race().Run()))
+		assert.Equal(t, "quorum[1]/upload", path(ok.Quorum(2, up).Run()))
+		bad := Tasklet(func(context.Context) error { return errX })
+		assert.Equal(t, "orelse[1]/upload", path(bad.OrElse(up).Run()))
+		assert.Equal(t, "finally[1]/upload", path(ok.Finally(up).Run()))
+
+		hedged, _ := up.Hedge(time.Hour, 1)
+		assert.Equal(t, "hedge[0]/upload", path(hedged.Run()))
+
+		err = ok.Seq(Tasklet(func(ctx context.Context) error {
+			return Permanent(up(ctx))
+		})).Run()
+		assert.Equal(t, "seq[1]/upload", path(err))
+		assert.True(t, IsPermanent()(err))
+
+		err = ok.Seq(Tasklet(func(ctx context.Context) error {
+			return fmt.Errorf("wrapped: %w", up(ctx))
+		})).Run()
+		assert.Equal(t, "seq[1]/upload", path(err))
+		assert.Equal(t, "seq[1]/upload: wrapped: x", err.Error())
+		assert.True(t, errors.Is(err, errX))
+	})
+
+	t.Run("Unnamed", func(t *testing.T) {
+		f := Closure(Fail(errX)).Seq()
+		assert.Equal(t, errX, f())
+		assert.Nil(t, Closure(Fail(nil)).Named("ok")())
+	})
+
+	t.Run("Loops", func(t *testing.T) {
+		f := Statelet(func(StateLoader) error { return errX }).Named("f")
+		assert.Equal(t, "while#3/f: x",
+			f.While(TriesLt(3))(new(State)).Error())
+		assert.Equal(t, "until#2/f: x",
+			f.Until(TriesEq(2))(new(State)).Error())
+	})
+
+	t.Run("Fn", func(t *testing.T) {
+		xs, err := SeqFn(
+			ret(1, 0),
+			Fn[int](func() (int, error) { return 0, errX }).Named("b"),
+		)()
+		assert.Nil(t, xs)
+		assert.Equal(t, "seq[1]/b: x", err.Error())
+	})
+}
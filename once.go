@@ -0,0 +1,128 @@
+package ftl
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// OnceGate keeps track of whether a function made with OnceSuccess
+// has succeeded yet.
+type OnceGate struct {
+	lock chan struct{} // held while running
+	done uint32
+}
+
+func newOnceGate() *OnceGate {
+	return &OnceGate{lock: make(chan struct{}, 1)}
+}
+
+// Done reports whether the function has succeeded.
+func (g *OnceGate) Done() bool {
+	return atomic.LoadUint32(&g.done) == 1
+}
+
+// Reset the gate, so that the function runs again on its next call
+// as if it never succeeded.
+func (g *OnceGate) Reset() {
+	atomic.StoreUint32(&g.done, 0)
+}
+
+// do runs f if it hasn't succeeded yet, one call at a time. If ctx
+// is cancelled while waiting for another call, it gives up.
+func (g *OnceGate) do(ctx context.Context, f func() error) error {
+	if g.Done() {
+		return nil
+	}
+	select {
+	case g.lock <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-g.lock }()
+
+	if g.Done() {
+		return nil // succeeded while we were waiting
+	}
+	if err := f(); err != nil {
+		return err
+	}
+	atomic.StoreUint32(&g.done, 1)
+	return nil
+}
+
+// OnceSuccess is like Once, but only remembers success. Until f
+// succeeds, every call runs it, one at a time.
+func (f Closure) OnceSuccess() (Closure, *OnceGate) {
+	g := newOnceGate()
+	return func() error {
+		return g.do(context.Background(), f)
+	}, g
+}
+
+// OnceSuccess is like Closure.OnceSuccess. Calls waiting for another
+// one to finish give up if their context is cancelled.
+func (f Tasklet) OnceSuccess() (Tasklet, *OnceGate) {
+	g := newOnceGate()
+	return func(ctx context.Context) error {
+		return g.do(ctx, f.Ap(ctx))
+	}, g
+}
+
+// OnceSuccess is like Closure.OnceSuccess.
+func (f Statelet) OnceSuccess() (Statelet, *OnceGate) {
+	g := newOnceGate()
+	return func(state StateLoader) error {
+		return g.do(context.Background(), f.Ap(state))
+	}, g
+}
+
+// OnceSuccess is like Tasklet.OnceSuccess.
+func (f Routine) OnceSuccess() (Routine, *OnceGate) {
+	g := newOnceGate()
+	return func(ctx context.Context, state StateLoader) error {
+		return g.do(ctx, f.Ap(ctx, state))
+	}, g
+}
+
+// OnceSuccess is like Closure.OnceSuccess. Once f succeeds, every
+// call produces the same T.
+func (f Fn[T]) OnceSuccess() (Fn[T], *OnceGate) {
+	var (
+		g = newOnceGate()
+		x atomic.Pointer[T]
+	)
+	return func() (T, error) {
+		return f.onceSuccess(context.Background(), g, &x)
+	}, g
+}
+
+// OnceSuccess is like Tasklet.OnceSuccess. Once f succeeds, every
+// call produces the same T.
+func (f Task[T]) OnceSuccess() (Task[T], *OnceGate) {
+	var (
+		g = newOnceGate()
+		x atomic.Pointer[T]
+	)
+	return func(ctx context.Context) (T, error) {
+		return f.Ap(ctx).onceSuccess(ctx, g, &x)
+	}, g
+}
+
+// onceSuccess runs f through g, keeping the value it produces on
+// success in x.
+func (f Fn[T]) onceSuccess(ctx context.Context, g *OnceGate,
+	x *atomic.Pointer[T],
+) (T, error) {
+	err := g.do(ctx, func() error {
+		y, err := f()
+		if err == nil {
+			x.Store(&y)
+		}
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *x.Load(), nil
+}
@@ -0,0 +1,88 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnceSuccess(t *testing.T) {
+	errX := errors.New("x")
+
+	t.Run("Retries until success", func(t *testing.T) {
+		var i int
+		f, g := Closure(func() error {
+			i++
+			if i < 3 {
+				return errX
+			}
+			return nil
+		}).OnceSuccess()
+
+		assert.Equal(t, errX, f())
+		assert.Equal(t, errX, f())
+		assert.False(t, g.Done())
+		assert.NoError(t, f())
+		assert.NoError(t, f())
+		assert.True(t, g.Done())
+		assert.Equal(t, 3, i)
+
+		g.Reset()
+		assert.NoError(t, f())
+		assert.Equal(t, 4, i)
+	})
+
+	t.Run("Serialised", func(t *testing.T) {
+		var cur, peak, runs int32
+		f, _ := Tasklet(func(_ context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return gauge(&cur, &peak)()
+		}).OnceSuccess()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, f.Run())
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), peak)
+		assert.Equal(t, int32(1), runs)
+	})
+
+	t.Run("Waiting cancels", func(t *testing.T) {
+		f, _ := sleeper(time.Second).OnceSuccess()
+		go f.Run()
+		time.Sleep(5 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, f(ctx))
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		var i int
+		f, _ := Fn[int](func() (int, error) {
+			i++
+			if i == 1 {
+				return 1, errX
+			}
+			return i, nil
+		}).OnceSuccess()
+
+		x, err := f()
+		assert.Equal(t, errX, err)
+		assert.Equal(t, 0, x)
+		x, _ = f()
+		assert.Equal(t, 2, x)
+		x, _ = f()
+		assert.Equal(t, 2, x)
+	})
+}
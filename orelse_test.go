@@ -0,0 +1,46 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrElse(t *testing.T) {
+	errX, errY := errors.New("x"), errors.New("y")
+
+	t.Run("Fallback", func(t *testing.T) {
+		var i, j int
+		err := Fail(errX).OrElse(Fail(errY), succ(&i), succ(&j))()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, i)
+		assert.Equal(t, 0, j)
+	})
+
+	t.Run("All fail", func(t *testing.T) {
+		err := Fail(errX).OrElse(Fail(errY))()
+		assert.Equal(t, MultiError{{0, errX}, {1, errY}}, err)
+	})
+
+	t.Run("Ineligible", func(t *testing.T) {
+		var i int
+		err := Fail(errX).OrElseIf(Error(errX).Not(), succ(&i))()
+		assert.Equal(t, errX, err)
+		assert.Equal(t, 0, i)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var i int
+		err := Tasklet(func(_ context.Context) error {
+			cancel()
+			return errX
+		}).OrElse(func(_ context.Context) error {
+			return succ(&i)()
+		})(ctx)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 0, i)
+	})
+}
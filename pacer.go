@@ -0,0 +1,185 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrStopped is returned by calls of a function whose Pacer has been
+// stopped.
+var ErrStopped = errors.New("ftl: stopped")
+
+// Pacer controls a function made with Debounce or Throttle. Calls of
+// the function are coalesced into executions of the function it
+// wraps, and wait for and return the result of the execution they
+// were coalesced into.
+type Pacer struct {
+	f        Tasklet
+	d        time.Duration
+	throttle bool
+
+	ctx    context.Context // given to executions
+	cancel func()
+	run    sync.Mutex // held during executions
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending *paced // calls waiting for the next execution
+	last    time.Time
+	stopped bool
+}
+
+// paced is the result of an execution, shared by the calls that
+// were coalesced into it.
+type paced struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// finish sets the result of c, unless it's been set already.
+func (c *paced) finish(err error) {
+	c.once.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
+
+func newPacer(f Tasklet, d time.Duration, throttle bool) *Pacer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pacer{
+		f:        f,
+		d:        d,
+		throttle: throttle,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// call schedules an execution, or joins the pending one, and waits
+// for it. If ctx is cancelled, the call stops waiting but the
+// execution still happens.
+func (p *Pacer) call(ctx context.Context) error {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return ErrStopped
+	}
+
+	c := p.pending
+	if c != nil && !p.throttle && !p.timer.Stop() {
+		c = nil // it's firing already, so start over
+	}
+	switch {
+	case c != nil && !p.throttle:
+		p.timer.Reset(p.d) // wait for quiet again
+
+	case c == nil:
+		c = &paced{done: make(chan struct{})}
+		p.pending = c
+
+		wait := p.d
+		if p.throttle {
+			wait = p.d - time.Since(p.last)
+		}
+		p.timer = time.AfterFunc(wait, func() { p.fire(c) })
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fire executes the calls coalesced into c.
+func (p *Pacer) fire(c *paced) {
+	p.run.Lock()
+	defer p.run.Unlock()
+
+	p.mu.Lock()
+	if p.pending == c {
+		p.pending = nil
+	}
+	p.last = time.Now()
+	stopped := p.stopped
+	p.mu.Unlock()
+
+	if stopped {
+		c.finish(ErrStopped)
+		return
+	}
+	c.finish(p.f(p.ctx))
+}
+
+// Flush executes any pending calls right away, and waits for them
+// to finish.
+func (p *Pacer) Flush() {
+	p.mu.Lock()
+	c := p.pending
+	flush := c != nil && p.timer.Stop()
+	p.mu.Unlock()
+
+	if c == nil {
+		return
+	}
+	if flush {
+		p.fire(c)
+	}
+	<-c.done // if the timer beat us to it, wait for it instead
+}
+
+// Stop the pacer. Pending calls return ErrStopped without being
+// executed, running executions are interrupted, and future calls
+// return ErrStopped.
+func (p *Pacer) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	if c := p.pending; c != nil {
+		p.timer.Stop()
+		p.pending = nil
+		c.finish(ErrStopped)
+	}
+	p.mu.Unlock()
+
+	p.cancel()
+}
+
+// Debounce coalesces calls of f into one execution, once d has
+// passed without any more calls.
+func (f Closure) Debounce(d time.Duration) (Closure, *Pacer) {
+	p := newPacer(func(_ context.Context) error { return f() }, d, false)
+	return func() error {
+		return p.call(context.Background())
+	}, p
+}
+
+// Throttle executes f at most once every d. Calls made less than d
+// after the last execution started are coalesced into one execution
+// once d has passed.
+func (f Closure) Throttle(d time.Duration) (Closure, *Pacer) {
+	p := newPacer(func(_ context.Context) error { return f() }, d, true)
+	return func() error {
+		return p.call(context.Background())
+	}, p
+}
+
+// Debounce is like Closure.Debounce. f is given a context that's
+// cancelled by Stop. Calls stop waiting if their context is
+// cancelled, but their execution still happens.
+func (f Tasklet) Debounce(d time.Duration) (Tasklet, *Pacer) {
+	p := newPacer(f, d, false)
+	return p.call, p
+}
+
+// Throttle is like Closure.Throttle. f is given a context that's
+// cancelled by Stop. Calls stop waiting if their context is
+// cancelled, but their execution still happens.
+func (f Tasklet) Throttle(d time.Duration) (Tasklet, *Pacer) {
+	p := newPacer(f, d, true)
+	return p.call, p
+}
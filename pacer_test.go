@@ -0,0 +1,183 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebounce(t *testing.T) {
+	errX := errors.New("x")
+
+	t.Run("Coalesces bursts", func(t *testing.T) {
+		var runs int32
+		f, _ := Closure(func() error {
+			atomic.AddInt32(&runs, 1)
+			return errX
+		}).Debounce(20 * time.Millisecond)
+
+		var wg sync.WaitGroup
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Equal(t, errX, f())
+			}()
+			time.Sleep(5 * time.Millisecond)
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+		assert.True(t, time.Since(start) >= 40*time.Millisecond)
+
+		assert.Equal(t, errX, f())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		var runs int32
+		f, p := Closure(func() error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}).Debounce(time.Hour)
+
+		done := make(chan error)
+		go func() { done <- f() }()
+		time.Sleep(10 * time.Millisecond)
+
+		p.Flush()
+		assert.NoError(t, <-done)
+		assert.Equal(t, int32(1), runs)
+
+		p.Flush()
+		assert.Equal(t, int32(1), runs)
+	})
+
+	t.Run("Flush waits for a firing timer", func(t *testing.T) {
+		var runs int32
+		f, p := Closure(func() error {
+			time.Sleep(30 * time.Millisecond)
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}).Debounce(time.Millisecond)
+
+		go f()
+		time.Sleep(10 * time.Millisecond) // first execution running
+		go f()
+		time.Sleep(10 * time.Millisecond) // second timer fired, waiting
+
+		p.Flush()
+		assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+	})
+
+	t.Run("Quiet after a fired timer", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			starts []time.Time
+		)
+		f, _ := Closure(func() error {
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}).Debounce(50 * time.Millisecond)
+
+		var (
+			wg    sync.WaitGroup
+			start = time.Now()
+			last  time.Time
+		)
+		// the second execution is waiting for the first when the
+		// third call comes in, and the fourth comes in while the
+		// second runs
+		for _, at := range []time.Duration{0, 60, 120, 240} {
+			time.Sleep(time.Until(start.Add(at * time.Millisecond)))
+			last = time.Now()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f()
+			}()
+		}
+		wg.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		quiet := starts[len(starts)-1].Sub(last)
+		assert.True(t, quiet >= 50*time.Millisecond, "ran %v after the last call", quiet)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		f, p := Tasklet(func(ctx context.Context) error {
+			t.Error("ran")
+			return nil
+		}).Debounce(time.Hour)
+
+		done := make(chan error)
+		go func() { done <- f.Run() }()
+		time.Sleep(10 * time.Millisecond)
+
+		p.Stop()
+		assert.Equal(t, ErrStopped, <-done)
+		assert.Equal(t, ErrStopped, f.Run())
+	})
+
+	t.Run("Stop interrupts", func(t *testing.T) {
+		f, p := Tasklet(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).Debounce(0)
+
+		done := make(chan error)
+		go func() { done <- f.Run() }()
+		time.Sleep(10 * time.Millisecond)
+
+		p.Stop()
+		assert.Equal(t, context.Canceled, <-done)
+	})
+
+	t.Run("Caller cancels", func(t *testing.T) {
+		var runs int32
+		f, p := Tasklet(func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}).Debounce(20 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, context.Canceled, f(ctx))
+
+		p.Flush()
+		assert.Equal(t, int32(1), runs)
+	})
+}
+
+func TestThrottle(t *testing.T) {
+	var runs int32
+	f, _ := Tasklet(func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}).Throttle(30 * time.Millisecond)
+
+	start := time.Now()
+	assert.NoError(t, f.Run())
+	assert.True(t, time.Since(start) < 20*time.Millisecond, "leading")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, f.Run())
+		}()
+	}
+	wg.Wait()
+	assert.True(t, time.Since(start) >= 30*time.Millisecond, "trailing")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
@@ -0,0 +1,57 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned in place of a panic by functions that
+// have been made to Recover.
+type PanicError struct {
+	// Value passed to panic.
+	Value interface{}
+
+	// Stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("ftl: recovered panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recovers converts a panic into a *PanicError, storing it in err.
+// It must be deferred directly.
+func recovers(err *error) {
+	if v := recover(); v != nil {
+		*err = &PanicError{Value: v, Stack: debug.Stack()}
+	}
+}
+
+type recoverKey struct{}
+
+// withRecover marks ctx so that panics in the branches that
+// combinators spawn within it are recovered too.
+func withRecover(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recoverKey{}, true)
+}
+
+// recovered reports whether panics within ctx are to be recovered.
+func recovered(ctx context.Context) bool {
+	return ctx.Value(recoverKey{}) != nil
+}
+
+// Panicked holds for errors that are, or wrap, a recovered panic.
+func Panicked() Predicate {
+	return func(err error) bool {
+		var p *PanicError
+		return errors.As(err, &p)
+	}
+}
@@ -0,0 +1,82 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	t.Run("Par", func(t *testing.T) {
+		var i int
+		err := Closure.Par(
+			succ(&i),
+			Closure(func() error { panic("boom") }).Recover(),
+		)()
+
+		var perr *PanicError
+		assert.True(t, errors.As(err, &perr))
+		assert.Equal(t, "boom", perr.Value)
+		assert.NotEmpty(t, perr.Stack)
+		assert.True(t, Panicked()(err))
+		assert.True(t, NotNil()(err))
+	})
+
+	t.Run("Wrapped", func(t *testing.T) {
+		boom := Closure(func() error { panic("boom") }).Recover()
+		assert.True(t, Panicked()(boom.Named("boom")()))
+		assert.True(t, Panicked()(Fail(nil).ParAll(boom)()))
+		assert.False(t, Panicked()(Fail(errors.New("x"))()))
+	})
+
+	t.Run("Unwrap", func(t *testing.T) {
+		errX := errors.New("x")
+		err := Tasklet(func(_ context.Context) error {
+			panic(errX)
+		}).Recover().Run()
+		assert.True(t, errors.Is(err, errX))
+	})
+
+	t.Run("RunSigMRecover drains state", func(t *testing.T) {
+		var unloaded bool
+		err := Routine(func(_ context.Context, state StateLoader) error {
+			state.LoadUnload(func(_ context.Context) error {
+				unloaded = true
+				return nil
+			})
+			panic("boom")
+		}).RunSigMRecover(context.Background(), nil)
+
+		assert.True(t, Panicked()(err))
+		assert.True(t, unloaded)
+	})
+
+	t.Run("RunSigMRecover gives up on held state", func(t *testing.T) {
+		defer func(d time.Duration) { PanicUnload = d }(PanicUnload)
+		PanicUnload = 20 * time.Millisecond
+
+		err := Routine(func(_ context.Context, state StateLoader) error {
+			state.Load()
+			panic("boom")
+		}).RunSigMRecover(context.Background(), nil)
+		assert.True(t, Panicked()(err))
+	})
+
+	t.Run("RunSigMRecover catches branches", func(t *testing.T) {
+		ok := Routine(func(context.Context, StateLoader) error { return nil })
+		boom := Routine(func(context.Context, StateLoader) error { panic("boom") })
+
+		for name, f := range map[string]Routine{
+			"Par":    ok.Par(boom),
+			"ParN":   ok.ParN(1, boom),
+			"ParAll": ok.ParAll(boom),
+			"Race":   boom.Race(boom),
+		} {
+			err := f.RunSigMRecover(context.Background(), nil)
+			assert.True(t, Panicked()(err), name)
+		}
+	})
+}
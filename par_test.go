@@ -0,0 +1,108 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gauge returns a Closure that tracks the peak number of concurrent
+// calls in peak.
+func gauge(cur, peak *int32) Closure {
+	return func() error {
+		n := atomic.AddInt32(cur, 1)
+		for {
+			p := atomic.LoadInt32(peak)
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(cur, -1)
+		return nil
+	}
+}
+
+func TestParN(t *testing.T) {
+	t.Run("Closure", func(t *testing.T) {
+		var cur, peak int32
+		gs := make([]Closure, 100)
+		for i := range gs {
+			gs[i] = gauge(&cur, &peak)
+		}
+		assert.NoError(t, gauge(&cur, &peak).ParN(4, gs...)())
+		assert.True(t, peak <= 4)
+		assert.True(t, peak > 0)
+	})
+
+	t.Run("Tasklet stops on failure", func(t *testing.T) {
+		var (
+			errX = errors.New("x")
+			runs int32
+		)
+		gs := make([]Tasklet, 100)
+		for i := range gs {
+			gs[i] = func(_ context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}
+		}
+		err := Tasklet(func(_ context.Context) error {
+			return errX
+		}).ParN(1, gs...).Run()
+		assert.Equal(t, errX, err)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+	})
+
+	t.Run("Tasklet cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := Tasklet.ParN(retErr, 2, retErr, retErr)(ctx)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestParAll(t *testing.T) {
+	errX, errY := errors.New("x"), errors.New("y")
+
+	t.Run("Closure", func(t *testing.T) {
+		var i int32
+		inc := func() error { atomic.AddInt32(&i, 1); return nil }
+		err := Closure.ParAll(inc, Fail(errX), inc, Fail(errY))()
+		assert.Equal(t, MultiError{{1, errX}, {3, errY}}, err)
+		assert.Equal(t, int32(2), i)
+
+		assert.True(t, errors.Is(err, errX))
+		assert.True(t, Error(errY)(err))
+		assert.False(t, Error(errors.New("z"))(err))
+
+		var be BranchError
+		assert.True(t, errors.As(err, &be))
+		assert.Equal(t, 1, be.Index)
+
+		assert.NoError(t, Closure.ParAll(inc, inc)())
+	})
+
+	t.Run("Tasklet waits for everything", func(t *testing.T) {
+		var done int32
+		err := Tasklet.ParAll(
+			failAfter(0, errX),
+			Tasklet.Seq(sleeper(10*time.Millisecond), func(_ context.Context) error {
+				atomic.StoreInt32(&done, 1)
+				return nil
+			}),
+		).Run()
+		assert.Equal(t, MultiError{{0, errX}}, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&done))
+	})
+
+	t.Run("Fn keeps values", func(t *testing.T) {
+		xs, err := ParAllFn(ret(1, 0), func() (int, error) { return 0, errX }, ret(3, 0))()
+		assert.Equal(t, []int{1, 0, 3}, xs)
+		assert.Equal(t, MultiError{{1, errX}}, err)
+	})
+}
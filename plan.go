@@ -0,0 +1,216 @@
+package ftl
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Builder is a function type with the combinators that Planned
+// records plans for.
+type Builder[F any] interface {
+	Seq(gs ...F) F
+	Par(gs ...F) F
+	While(p Predicate) F
+	Until(p Predicate) F
+	Ite(p Predicate, g, z F) F
+	Mu(mu sync.Locker) F
+	Once() F
+	Named(name string) F
+}
+
+// Plan describes what a function does, as a tree of the combinators
+// it was built with.
+type Plan struct {
+	// Kind of combinator, such as "seq" or "while". Functions that
+	// weren't built with a combinator are of kind "func".
+	Kind string
+
+	// Name given with Named, or the name of the Go function for
+	// those of kind "func".
+	Name string
+
+	// Children are the plans of the functions combined, in order.
+	// For an Ite, they're the condition, then and else functions.
+	Children []*Plan
+}
+
+// Planned is a function along with the plan of how it was built.
+// Its combinators build F with the combinators of the same name,
+// and record their plans as they go. Then and Join do the same for
+// any other combinators.
+type Planned[F Builder[F]] struct {
+	F    F
+	Plan *Plan
+}
+
+// Planning starts recording the plan of compositions of f.
+func Planning[F Builder[F]](f F) Planned[F] {
+	name := "?"
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		name = fn.Name()
+	}
+	return Planned[F]{f, &Plan{Kind: "func", Name: name}}
+}
+
+// Describe returns the plan of f.
+func Describe[F Builder[F]](f Planned[F]) *Plan {
+	return f.Plan
+}
+
+// planned records h as the result of combining f with gs.
+func planned[F Builder[F]](h F, kind, name string, f Planned[F],
+	gs []Planned[F],
+) Planned[F] {
+	p := &Plan{Kind: kind, Name: name, Children: []*Plan{f.Plan}}
+	for _, g := range gs {
+		p.Children = append(p.Children, g.Plan)
+	}
+	return Planned[F]{h, p}
+}
+
+// funcs returns the functions of ps.
+func funcs[F Builder[F]](ps []Planned[F]) []F {
+	fs := make([]F, len(ps))
+	for i := range ps {
+		fs[i] = ps[i].F
+	}
+	return fs
+}
+
+func (f Planned[F]) Seq(gs ...Planned[F]) Planned[F] {
+	return planned(f.F.Seq(funcs(gs)...), "seq", "", f, gs)
+}
+
+func (f Planned[F]) Par(gs ...Planned[F]) Planned[F] {
+	return planned(f.F.Par(funcs(gs)...), "par", "", f, gs)
+}
+
+func (f Planned[F]) While(p Predicate) Planned[F] {
+	return planned(f.F.While(p), "while", "", f, nil)
+}
+
+func (f Planned[F]) Until(p Predicate) Planned[F] {
+	return planned(f.F.Until(p), "until", "", f, nil)
+}
+
+func (f Planned[F]) Ite(p Predicate, g, z Planned[F]) Planned[F] {
+	return planned(f.F.Ite(p, g.F, z.F), "ite", "", f, []Planned[F]{g, z})
+}
+
+func (f Planned[F]) Mu(mu sync.Locker) Planned[F] {
+	return planned(f.F.Mu(mu), "mu", "", f, nil)
+}
+
+func (f Planned[F]) Once() Planned[F] {
+	return planned(f.F.Once(), "once", "", f, nil)
+}
+
+func (f Planned[F]) Named(name string) Planned[F] {
+	return planned(f.F.Named(name), "named", name, f, nil)
+}
+
+// Then applies g to f, recording it in the plan as a combinator of
+// the given kind. It keeps the plan through combinators Planned
+// doesn't have, such as Timeout or Recover:
+//
+//	p.Then("timeout", func(r Routine) Routine { return r.Timeout(d) })
+func (f Planned[F]) Then(kind string, g func(F) F) Planned[F] {
+	return planned(g(f.F), kind, "", f, nil)
+}
+
+// Join is like Then, for combinators of several functions, such as
+// Race or OrElse:
+//
+//	p.Join("race", Routine.Race, q, r)
+func (f Planned[F]) Join(kind string, g func(F, ...F) F, gs ...Planned[F]) Planned[F] {
+	return planned(g(f.F, funcs(gs)...), kind, "", f, gs)
+}
+
+func (p *Plan) label() string {
+	switch {
+	case p.Kind == "func":
+		return p.Name
+	case p.Name != "":
+		return p.Kind + " " + strconv.Quote(p.Name)
+	}
+	return p.Kind
+}
+
+// edge labels the edge to the ith child of p, if it needs one.
+func (p *Plan) edge(i int) string {
+	if p.Kind == "ite" && i < 3 {
+		return [...]string{"if", "then", "else"}[i]
+	}
+	return ""
+}
+
+// walk p depth first, numbering plans in the order they're visited.
+// visit is called for every plan with its number, the number of its
+// parent (-1 for p), its depth and the label of the edge from its
+// parent.
+func (p *Plan) walk(visit func(n, parent, depth int, edge string, q *Plan)) {
+	n := 0
+	var rec func(parent, depth int, edge string, q *Plan)
+	rec = func(parent, depth int, edge string, q *Plan) {
+		id := n
+		n++
+		visit(id, parent, depth, edge, q)
+		for i, c := range q.Children {
+			rec(id, depth+1, q.edge(i), c)
+		}
+	}
+	rec(-1, 0, "", p)
+}
+
+// String renders p as an indented tree.
+func (p *Plan) String() string {
+	var b strings.Builder
+	p.walk(func(_, _, depth int, edge string, q *Plan) {
+		b.WriteString(strings.Repeat("  ", depth))
+		if edge != "" {
+			b.WriteString(edge + ": ")
+		}
+		b.WriteString(q.label() + "\n")
+	})
+	return b.String()
+}
+
+// DOT renders p as a Graphviz digraph.
+func (p *Plan) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph plan {\n")
+	p.walk(func(n, parent, _ int, edge string, q *Plan) {
+		fmt.Fprintf(&b, "\tn%d [label=%s];\n", n, strconv.Quote(q.label()))
+		switch {
+		case parent < 0:
+		case edge != "":
+			fmt.Fprintf(&b, "\tn%d -> n%d [label=%s];\n", parent, n, strconv.Quote(edge))
+		default:
+			fmt.Fprintf(&b, "\tn%d -> n%d;\n", parent, n)
+		}
+	})
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders p as a Mermaid flowchart.
+func (p *Plan) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	p.walk(func(n, parent, _ int, edge string, q *Plan) {
+		label := strings.ReplaceAll(q.label(), `"`, "#quot;")
+		fmt.Fprintf(&b, "\tn%d[\"%s\"]\n", n, label)
+		switch {
+		case parent < 0:
+		case edge != "":
+			fmt.Fprintf(&b, "\tn%d -->|%s| n%d\n", parent, edge, n)
+		default:
+			fmt.Fprintf(&b, "\tn%d --> n%d\n", parent, n)
+		}
+	})
+	return b.String()
+}
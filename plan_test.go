@@ -0,0 +1,84 @@
+package ftl
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fetch(context.Context, StateLoader) error  { return nil }
+func upload(context.Context, StateLoader) error { return nil }
+
+func TestDescribe(t *testing.T) {
+	var (
+		mu sync.Mutex
+		f  = Planning(Routine(fetch))
+		u  = Planning(Routine(upload))
+	)
+	deploy := f.Seq(
+		u.Until(Nil()).Named("upload"),
+		f.Ite(Nil(), u.Mu(&mu), f.Once()),
+	).Par(f).Named("deploy")
+
+	p := Describe(deploy)
+	assert.Equal(t, "named", p.Kind)
+	assert.Equal(t, "deploy", p.Name)
+
+	t.Run("String", func(t *testing.T) {
+		assert.Equal(t, strings.Join([]string{
+			`named "deploy"`,
+			`  par`,
+			`    seq`,
+			`      github.com/nytopop/ftl.fetch`,
+			`      named "upload"`,
+			`        until`,
+			`          github.com/nytopop/ftl.upload`,
+			`      ite`,
+			`        if: github.com/nytopop/ftl.fetch`,
+			`        then: mu`,
+			`          github.com/nytopop/ftl.upload`,
+			`        else: once`,
+			`          github.com/nytopop/ftl.fetch`,
+			`    github.com/nytopop/ftl.fetch`,
+			``,
+		}, "\n"), p.String())
+	})
+
+	t.Run("DOT", func(t *testing.T) {
+		dot := p.DOT()
+		assert.True(t, strings.HasPrefix(dot, "digraph plan {\n"))
+		assert.Contains(t, dot, "\tn0 [label=\"named \\\"deploy\\\"\"];\n")
+		assert.Contains(t, dot, "\tn0 -> n1;\n")
+		assert.Contains(t, dot, "\tn7 -> n8 [label=\"if\"];\n")
+	})
+
+	t.Run("Mermaid", func(t *testing.T) {
+		md := p.Mermaid()
+		assert.True(t, strings.HasPrefix(md, "flowchart TD\n"))
+		assert.Contains(t, md, "\tn0[\"named #quot;deploy#quot;\"]\n")
+		assert.Contains(t, md, "\tn0 --> n1\n")
+		assert.Contains(t, md, "\tn7 -->|then| n9\n")
+	})
+
+	t.Run("Runs", func(t *testing.T) {
+		assert.NoError(t, deploy.F(context.Background(), new(State)))
+	})
+
+	t.Run("Other combinators", func(t *testing.T) {
+		start := f.Join("race", Routine.Race, u).Then("timeout", func(r Routine) Routine {
+			return r.Timeout(time.Second)
+		})
+		assert.Equal(t, strings.Join([]string{
+			`timeout`,
+			`  race`,
+			`    github.com/nytopop/ftl.fetch`,
+			`    github.com/nytopop/ftl.upload`,
+			``,
+		}, "\n"), Describe(start).String())
+		assert.NoError(t, start.F(context.Background(), new(State)))
+	})
+}
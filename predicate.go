@@ -2,6 +2,8 @@ package ftl
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -52,49 +54,54 @@ func False() Predicate {
 	return func(_ error) bool { return false }
 }
 
-func TriesEq(n int) Predicate {
-	var tries int
+// tries returns a predicate that counts how many times it has been
+// evaluated, and holds if hold does for that count.
+func tries(hold func(tries int) bool) Predicate {
+	var n int64
 	return func(_ error) bool {
-		tries++
-		return tries == n
+		return hold(int(atomic.AddInt64(&n, 1)))
 	}
 }
 
+func TriesEq(n int) Predicate {
+	return tries(func(tries int) bool { return tries == n })
+}
+
 func TriesLt(n int) Predicate {
-	var tries int
-	return func(_ error) bool {
-		tries++
-		return tries < n
-	}
+	return tries(func(tries int) bool { return tries < n })
 }
 
 func TriesLte(n int) Predicate {
-	var tries int
-	return func(_ error) bool {
-		tries++
-		return tries <= n
-	}
+	return tries(func(tries int) bool { return tries <= n })
 }
 
 func TriesGt(n int) Predicate {
-	var tries int
-	return func(_ error) bool {
-		tries++
-		return tries > n
-	}
+	return tries(func(tries int) bool { return tries > n })
 }
 
 func TriesGte(n int) Predicate {
-	var tries int
-	return func(_ error) bool {
-		tries++
-		return tries >= n
-	}
+	return tries(func(tries int) bool { return tries >= n })
 }
 
+// Error holds if the cause of an error is err, or if it matches err
+// according to errors.Is. For a MultiError, it holds if that's true
+// of any of its errors.
 func Error(err error) Predicate {
+	is := Is(err)
 	return func(er error) bool {
-		return errors.Cause(er) == err
+		if is(er) {
+			return true
+		}
+		cause := errors.Cause(er)
+		if m, ok := cause.(MultiError); ok {
+			for i := range m {
+				if Error(err)(m[i].Err) {
+					return true
+				}
+			}
+			return false
+		}
+		return cause == err
 	}
 }
 
@@ -122,24 +129,58 @@ func NotDone(ctx context.Context) Predicate {
 	}
 }
 
+// Backoff holds after waiting, doubling how long it waits every time
+// it's evaluated from start up to ceil. It doesn't wait the first
+// time. Errors marked with Permanent make it not hold right away,
+// and errors marked with RetryAfter make it wait exactly as long as
+// they say to.
+//
+// Schedule.Backoff is more flexible, and can be interrupted. Under
+// Until, use BackoffUntil instead.
 func Backoff(start, ceil time.Duration) Predicate {
 	var (
+		mu    sync.Mutex
 		i     int
 		sleep = start
 	)
-	return func(_ error) bool {
+	permanent := IsPermanent()
+	return func(err error) bool {
+		if permanent(err) {
+			return false
+		}
+		if d, ok := retryAfter(err); ok {
+			time.Sleep(d)
+			return true
+		}
+
+		mu.Lock()
 		i++
 		if i == 1 {
+			mu.Unlock()
 			return true
 		}
 
-		time.Sleep(sleep)
-
+		d := sleep
 		sleep = sleep * 2
 		if sleep > ceil {
 			sleep = ceil
 		}
+		mu.Unlock()
+
+		time.Sleep(d)
 
 		return true
 	}
 }
+
+// BackoffUntil is Backoff for use with Until. It holds, ending the
+// loop, on success and on errors marked with Permanent. Otherwise it
+// waits like Backoff, and doesn't hold.
+func BackoffUntil(start, ceil time.Duration) Predicate {
+	return giveUp(Backoff(start, ceil))
+}
+
+// PredicateFactory makes predicates. Loops given one make a fresh
+// predicate every time they run, so that the state of predicates
+// like TriesEq or Backoff isn't shared between runs.
+type PredicateFactory func() Predicate
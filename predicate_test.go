@@ -0,0 +1,34 @@
+package ftl
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredicate(t *testing.T) {
+	t.Run("Each run gets a fresh predicate", func(t *testing.T) {
+		var i int
+		f := Closure(succ(&i)).UntilEach(func() Predicate {
+			return TriesEq(3)
+		})
+
+		assert.NoError(t, f())
+		assert.Equal(t, 3, i)
+		assert.NoError(t, f())
+		assert.Equal(t, 6, i)
+	})
+
+	t.Run("Shared under Par", func(t *testing.T) {
+		var i int32
+		p := TriesLte(100)
+		f := Closure(func() error {
+			atomic.AddInt32(&i, 1)
+			return nil
+		}).While(p)
+
+		assert.NoError(t, Closure.Par(f, f, f, f)())
+		assert.Equal(t, int32(104), i)
+	})
+}
@@ -0,0 +1,134 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrQuorum is returned by Quorum when more successes are required
+// than there are tasklets.
+var ErrQuorum = errors.New("ftl: quorum larger than number of tasklets")
+
+// race runs fs concurrently as steps of kind k, handing each result
+// to done as they arrive. Once done returns true, the remaining fs
+// are interrupted and done isn't called again. It returns once all
+// fs have returned.
+func race(ctx context.Context, k StepKind, fs []Tasklet,
+	done func(i int, err error) bool,
+) {
+	type result struct {
+		i   int
+		err error
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result, len(fs))
+	for i, f := range fs {
+		go func(i int, f Tasklet) {
+			results <- result{i, f.step(ctx, at(k, i))()}
+		}(i, f)
+	}
+
+	var stop bool
+	for range fs {
+		r := <-results
+		if !stop && done(r.i, r.err) {
+			stop = true
+			cancel() // interrupt the stragglers
+		}
+	}
+}
+
+// Race runs f and gs concurrently, returning nil as soon as any of
+// them succeeds and interrupting the rest. If they all fail, their
+// errors are returned together as a MultiError.
+func (f Tasklet) Race(gs ...Tasklet) Tasklet {
+	return func(ctx context.Context) error {
+		var (
+			won  bool
+			errs = make(MultiError, 0, 1+len(gs))
+		)
+		race(ctx, StepRace, append([]Tasklet{f}, gs...), func(i int, err error) bool {
+			if err == nil {
+				won = true
+				return true
+			}
+			errs = append(errs, BranchError{i, err})
+			return false
+		})
+		if won {
+			return nil
+		}
+		return errs.sorted()
+	}
+}
+
+// FirstOf runs f and gs concurrently, returning the result of
+// whichever returns first, error or not, and interrupting the rest.
+func (f Tasklet) FirstOf(gs ...Tasklet) Tasklet {
+	return func(ctx context.Context) error {
+		var first error
+		race(ctx, StepRace, append([]Tasklet{f}, gs...), func(_ int, err error) bool {
+			first = err
+			return true
+		})
+		return first
+	}
+}
+
+// Quorum runs f and gs concurrently, returning nil once k of them
+// have succeeded. It fails as soon as so many have failed that k
+// successes are no longer possible, returning the failures as a
+// MultiError. Either way, any stragglers are interrupted.
+func (f Tasklet) Quorum(k int, gs ...Tasklet) Tasklet {
+	return func(ctx context.Context) error {
+		fs := append([]Tasklet{f}, gs...)
+		if k > len(fs) {
+			return ErrQuorum
+		}
+		if k <= 0 {
+			return nil
+		}
+
+		var (
+			oks  int
+			errs = make(MultiError, 0, len(fs))
+		)
+		race(ctx, StepQuorum, fs, func(i int, err error) bool {
+			if err == nil {
+				oks++
+				return oks == k
+			}
+			errs = append(errs, BranchError{i, err})
+			return len(errs) > len(fs)-k
+		})
+		if oks == k {
+			return nil
+		}
+		return errs.sorted()
+	}
+}
+
+// Race is like Tasklet.Race.
+func (f Routine) Race(gs ...Routine) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		fs := make([]Tasklet, len(gs))
+		for i := range gs {
+			fs[i] = gs[i].Ap2(state)
+		}
+		return Tasklet.Race(f.Ap2(state), fs...)(ctx)
+	}
+}
+
+// FirstOf is like Tasklet.FirstOf.
+func (f Routine) FirstOf(gs ...Routine) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		fs := make([]Tasklet, len(gs))
+		for i := range gs {
+			fs[i] = gs[i].Ap2(state)
+		}
+		return Tasklet.FirstOf(f.Ap2(state), fs...)(ctx)
+	}
+}
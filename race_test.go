@@ -0,0 +1,78 @@
+package ftl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func failAfter(d time.Duration, err error) Tasklet {
+	return Tasklet.Seq(sleeper(d), func(_ context.Context) error {
+		return err
+	})
+}
+
+func TestRace(t *testing.T) {
+	errX, errY := errors.New("x"), errors.New("y")
+
+	t.Run("First success wins", func(t *testing.T) {
+		start := time.Now()
+		err := Tasklet.Race(
+			sleeper(time.Second),
+			failAfter(0, errX),
+			sleeper(5*time.Millisecond),
+		).Run()
+		assert.NoError(t, err)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("All fail", func(t *testing.T) {
+		err := Tasklet.Race(
+			failAfter(10*time.Millisecond, errX),
+			failAfter(0, errY),
+		).Run()
+
+		var errs MultiError
+		assert.True(t, errors.As(err, &errs))
+		assert.Equal(t, MultiError{{0, errX}, {1, errY}}, errs)
+		assert.True(t, errors.Is(err, errY))
+	})
+
+	t.Run("FirstOf", func(t *testing.T) {
+		err := Tasklet.FirstOf(
+			sleeper(time.Second),
+			failAfter(5*time.Millisecond, errX),
+		).Run()
+		assert.Equal(t, errX, err)
+	})
+
+	t.Run("Quorum", func(t *testing.T) {
+		start := time.Now()
+		err := Tasklet.Quorum(
+			sleeper(0),
+			2,
+			sleeper(time.Second),
+			failAfter(0, errX),
+			sleeper(5*time.Millisecond),
+		).Run()
+		assert.NoError(t, err)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("Quorum impossible", func(t *testing.T) {
+		start := time.Now()
+		err := Tasklet.Quorum(
+			failAfter(0, errX),
+			2,
+			sleeper(time.Second),
+			failAfter(5*time.Millisecond, errY),
+		).Run()
+		assert.Equal(t, MultiError{{0, errX}, {2, errY}}, err)
+		assert.True(t, time.Since(start) < time.Second)
+
+		assert.Equal(t, ErrQuorum, Tasklet.Quorum(retErr, 3, retErr).Run())
+	})
+}
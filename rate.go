@@ -0,0 +1,67 @@
+package ftl
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// outcomes is a ring buffer of whether recent calls failed.
+type outcomes struct {
+	mu     sync.Mutex
+	failed []bool
+	next   int // index of the oldest outcome
+	n      int // number of outcomes recorded, up to len(failed)
+	fails  int // number of recorded failures
+}
+
+// record an outcome, returning how many outcomes and failures are
+// in the buffer afterwards.
+func (o *outcomes) record(failed bool) (n, fails int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.n == len(o.failed) {
+		if o.failed[o.next] {
+			o.fails-- // evict the oldest
+		}
+	} else {
+		o.n++
+	}
+	o.failed[o.next] = failed
+	o.next = (o.next + 1) % len(o.failed)
+	if failed {
+		o.fails++
+	}
+	return o.n, o.fails
+}
+
+// ErrorRateAbove holds if more than ratio of the last window errors
+// it was evaluated on were non-nil. It doesn't hold until it has
+// been evaluated at least window times.
+//
+// To stop a worker once half of its last 100 runs have failed:
+//
+//	worker.While(ErrorRateAbove(0.5, 100).Not())
+func ErrorRateAbove(ratio float64, window int) Predicate {
+	if window < 1 {
+		window = 1
+	}
+	o := &outcomes{failed: make([]bool, window)}
+	return func(err error) bool {
+		n, fails := o.record(err != nil)
+		return n == window && float64(fails)/float64(n) > ratio
+	}
+}
+
+// ConsecutiveFailures holds if the last n errors it was evaluated
+// on were all non-nil.
+func ConsecutiveFailures(n int) Predicate {
+	var fails int64
+	return func(err error) bool {
+		if err == nil {
+			atomic.StoreInt64(&fails, 0)
+			return n <= 0
+		}
+		return atomic.AddInt64(&fails, 1) >= int64(n)
+	}
+}
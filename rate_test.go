@@ -0,0 +1,46 @@
+package ftl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRate(t *testing.T) {
+	errX := errors.New("x")
+
+	t.Run("ErrorRateAbove", func(t *testing.T) {
+		p := ErrorRateAbove(0.5, 4)
+		assert.False(t, p(errX))
+		assert.False(t, p(errX))
+		assert.False(t, p(errX)) // not yet a full window
+		assert.True(t, p(nil))   // 3 of 4
+		assert.False(t, p(nil))  // 2 of 4
+		assert.False(t, p(nil))  // 1 of 4
+		assert.False(t, p(errX)) // 1 of 4
+		assert.False(t, p(errX)) // 2 of 4
+		assert.True(t, p(errX))  // 3 of 4
+	})
+
+	t.Run("ConsecutiveFailures", func(t *testing.T) {
+		p := ConsecutiveFailures(2)
+		assert.False(t, p(errX))
+		assert.False(t, p(nil))
+		assert.False(t, p(errX))
+		assert.True(t, p(errX))
+	})
+
+	t.Run("Stops a worker", func(t *testing.T) {
+		var i int
+		err := Closure(func() error {
+			i++
+			if i%3 == 0 {
+				return nil
+			}
+			return errX
+		}).While(ErrorRateAbove(0.5, 10).Not())()
+		assert.Equal(t, errX, err)
+		assert.Equal(t, 10, i)
+	})
+}
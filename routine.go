@@ -87,7 +87,30 @@ func (f Routine) RunSigM(
 	ctx context.Context,
 	sigm map[os.Signal]time.Duration,
 ) error {
-	return f.runSigM(ctx, sigm, false)
+	return f.runSigM(ctx, sigm, false, false)
+}
+
+// RunSigMRecover is like RunSigM, but a panic in the routine, or in
+// any branch it runs concurrently, is returned as a *PanicError
+// instead of crashing the process. In that case, state is unloaded
+// before returning, waiting at most PanicUnload or until ctx is
+// cancelled. State held with Load by whatever panicked may never be
+// unloaded, hence the bound.
+func (f Routine) RunSigMRecover(
+	ctx context.Context,
+	sigm map[os.Signal]time.Duration,
+) error {
+	return f.runSigM(ctx, sigm, false, true)
+}
+
+// PanicUnload is how long RunSigMRecover waits for state to be
+// unloaded after a panic, or < 0 to wait until it is.
+var PanicUnload = 5 * time.Second
+
+// drain waits until all state has been unloaded, or ctx is done.
+func drain(ctx context.Context, state StateUnloader) {
+	for state.UnloadWait(ctx) != nil && ctx.Err() == nil {
+	}
 }
 
 // TODO: SIGINT 3x should force it to kill
@@ -95,7 +118,12 @@ func (f Routine) runSigM(
 	ctx context.Context,
 	sigm map[os.Signal]time.Duration,
 	force bool,
+	recovering bool,
 ) error {
+	if recovering {
+		f = f.Recover()
+	}
+
 	var (
 		state           = new(State)    // brand new state :)
 		sigs, sigCancel = listens(sigm) // listen for configured sigs
@@ -138,13 +166,7 @@ func (f Routine) runSigM(
 			state.Accepts(false)
 
 			// wait for loaded state to be unloaded
-			var err error
-			for {
-				err = state.UnloadWait(bg)
-				if err == nil {
-					break
-				}
-			}
+			drain(bg, state)
 
 			if force {
 				os.Exit(0)
@@ -159,6 +181,12 @@ func (f Routine) runSigM(
 			state.Accepts(false) // just in case
 			fCancel()            // release resources
 			if err := eg.Wait(); err != nil {
+				if recovering && Panicked()(err) {
+					// it may have left state loaded
+					waitCtx, waitCancel := withTimeout(ctx, PanicUnload)
+					drain(waitCtx, state)
+					waitCancel()
+				}
 				if force {
 					os.Exit(1)
 				}
@@ -196,14 +224,40 @@ func (f Routine) Seq(gs ...Routine) Routine {
 func (f Routine) Par(gs ...Routine) Routine {
 	return func(ctx context.Context, state StateLoader) error {
 		var eg errgroup.Group
-		eg.Go(f.Ap(ctx, state))
-		for _, g := range gs {
-			eg.Go(g.Ap(ctx, state))
+		eg.Go(f.Ap2(state).step(ctx, at(StepPar, 0)))
+		for i, g := range gs {
+			eg.Go(g.Ap2(state).step(ctx, at(StepPar, i+1)))
 		}
 		return eg.Wait()
 	}
 }
 
+// ParN is like Par, but runs at most limit routines at once. A limit
+// <= 0 means no limit. Once any of them fails, the rest are
+// interrupted and no more are started.
+func (f Routine) ParN(limit int, gs ...Routine) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		fs := make([]Tasklet, len(gs))
+		for i := range gs {
+			fs[i] = gs[i].Ap2(state)
+		}
+		return Tasklet.ParN(f.Ap2(state), limit, fs...)(ctx)
+	}
+}
+
+// ParAll is like Par, but waits for every routine to return. All of
+// their errors are returned as a MultiError.
+func (f Routine) ParAll(gs ...Routine) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		fs := make([]Tasklet, 0, 1+len(gs))
+		fs = append(fs, f.Ap2(state))
+		for _, g := range gs {
+			fs = append(fs, g.Ap2(state))
+		}
+		return goAll(ctx, fs)
+	}
+}
+
 func (f Routine) Ap(ctx context.Context, state StateLoader) Closure {
 	return func() error {
 		return f(ctx, state)
@@ -224,11 +278,7 @@ func (f Routine) Ap2(state StateLoader) Tasklet {
 
 func (f Routine) cond(p Predicate, exit bool) Routine {
 	return func(ctx context.Context, state StateLoader) error {
-		for {
-			if err := f(ctx, state); p(err) == exit {
-				return err
-			}
-		}
+		return f.Ap2(state).cond(p, exit)(ctx)
 	}
 }
 
@@ -240,13 +290,25 @@ func (f Routine) Until(p Predicate) Routine {
 	return Routine.cond(f, p, true)
 }
 
+// WhileEach is like While, but uses a fresh predicate from pf every
+// time it runs.
+func (f Routine) WhileEach(pf PredicateFactory) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.While(pf())(ctx, state)
+	}
+}
+
+// UntilEach is like Until, but uses a fresh predicate from pf every
+// time it runs.
+func (f Routine) UntilEach(pf PredicateFactory) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Until(pf())(ctx, state)
+	}
+}
+
 func (f Routine) Ite(p Predicate, g, z Routine) Routine {
 	return func(ctx context.Context, state StateLoader) error {
-		if err := f(ctx, state); p(err) {
-			return g(ctx, state)
-		} else {
-			return z(ctx, state)
-		}
+		return f.Ap2(state).Ite(p, g.Ap2(state), z.Ap2(state))(ctx)
 	}
 }
 
@@ -265,3 +327,64 @@ func (f Routine) Once() Routine {
 		return err
 	}
 }
+
+// Recover is like Tasklet.Recover.
+func (f Routine) Recover() Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Ap2(state).Recover()(ctx)
+	}
+}
+
+// Deadline interrupts f at time t. If f fails because of that,
+// ErrTimeout is returned.
+func (f Routine) Deadline(t time.Time) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Ap2(state).Deadline(t)(ctx)
+	}
+}
+
+// Timeout interrupts f after d. If f fails because of that,
+// ErrTimeout is returned.
+func (f Routine) Timeout(d time.Duration) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Ap2(state).Timeout(d)(ctx)
+	}
+}
+
+// SoftTimeout calls warn if f is still running after soft, and
+// interrupts it after hard like Timeout.
+func (f Routine) SoftTimeout(soft, hard time.Duration, warn func()) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Ap2(state).SoftTimeout(soft, hard, warn)(ctx)
+	}
+}
+
+// Finally is like Tasklet.Finally.
+func (f Routine) Finally(g Routine) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return f.Ap2(state).Finally(g.Ap2(state))(ctx)
+	}
+}
+
+// Bracket is like Tasklet.Bracket.
+func (acquire Routine) Bracket(use, release Routine) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		return acquire.Ap2(state).Bracket(use.Ap2(state), release.Ap2(state))(ctx)
+	}
+}
+
+// OrElse is like Tasklet.OrElse.
+func (f Routine) OrElse(gs ...Routine) Routine {
+	return f.OrElseIf(NotNil(), gs...)
+}
+
+// OrElseIf is like Tasklet.OrElseIf.
+func (f Routine) OrElseIf(p Predicate, gs ...Routine) Routine {
+	return func(ctx context.Context, state StateLoader) error {
+		fs := make([]Tasklet, len(gs))
+		for i := range gs {
+			fs[i] = gs[i].Ap2(state)
+		}
+		return Tasklet.OrElseIf(f.Ap2(state), p, fs...)(ctx)
+	}
+}
@@ -213,7 +213,7 @@ func (s *State) Wait(ctx context.Context) error {
 		).While(Nil()) // repeat until something happens
 	)
 
-	if err := loop(ctx); err == errDone {
+	if err := loop(ctx); errors.Is(err, errDone) {
 		return nil
 	} else {
 		return err
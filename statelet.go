@@ -31,6 +31,7 @@ func (f Statelet) RunSigM(
 		ctx,
 		sigm,
 		true,
+		false,
 	)
 }
 
@@ -45,12 +46,15 @@ func (f Statelet) Binds(bind func(x, y Statelet) Statelet,
 
 func (f Statelet) Seq(gs ...Statelet) Statelet {
 	return func(state StateLoader) error {
-		var err error
-		if err = f(state); err != nil {
+		var (
+			err error
+			bg  = context.Background()
+		)
+		if err = step(bg, at(StepSeq, 0), f.Ap(state)); err != nil {
 			return err
 		}
-		for _, g := range gs {
-			if err = g(state); err != nil {
+		for i, g := range gs {
+			if err = step(bg, at(StepSeq, i+1), g.Ap(state)); err != nil {
 				return err
 			}
 		}
@@ -59,16 +63,47 @@ func (f Statelet) Seq(gs ...Statelet) Statelet {
 }
 
 func (f Statelet) Par(gs ...Statelet) Statelet {
+	return func(state StateLoader) error {
+		var (
+			eg errgroup.Group
+			bg = context.Background()
+		)
+		eg.Go(f.Ap(state).step(bg, at(StepPar, 0)))
+		for i, g := range gs {
+			eg.Go(g.Ap(state).step(bg, at(StepPar, i+1)))
+		}
+		return eg.Wait()
+	}
+}
+
+// ParN is like Par, but runs at most limit statelets at once. A
+// limit <= 0 means no limit.
+func (f Statelet) ParN(limit int, gs ...Statelet) Statelet {
 	return func(state StateLoader) error {
 		var eg errgroup.Group
-		eg.Go(f.Ap(state))
+		fs := make([]Closure, 0, 1+len(gs))
+		fs = append(fs, f.Ap(state))
 		for _, g := range gs {
-			eg.Go(g.Ap(state))
+			fs = append(fs, g.Ap(state))
 		}
+		goN(context.Background(), nil, &eg, limit, lift(fs...))
 		return eg.Wait()
 	}
 }
 
+// ParAll is like Par, but waits for every statelet to return. All
+// of their errors are returned as a MultiError.
+func (f Statelet) ParAll(gs ...Statelet) Statelet {
+	return func(state StateLoader) error {
+		fs := make([]Closure, 0, 1+len(gs))
+		fs = append(fs, f.Ap(state))
+		for _, g := range gs {
+			fs = append(fs, g.Ap(state))
+		}
+		return goAll(context.Background(), lift(fs...))
+	}
+}
+
 func (f Statelet) Ap(state StateLoader) Closure {
 	return func() error {
 		return f(state)
@@ -77,8 +112,9 @@ func (f Statelet) Ap(state StateLoader) Closure {
 
 func (f Statelet) cond(p Predicate, exit bool) Statelet {
 	return func(state StateLoader) error {
-		for {
-			if err := f(state); p(err) == exit {
+		for n := 1; ; n++ {
+			err := step(context.Background(), attempt(exit, n), f.Ap(state))
+			if p(err) == exit {
 				return err
 			}
 		}
@@ -93,12 +129,29 @@ func (f Statelet) Until(p Predicate) Statelet {
 	return Statelet.cond(f, p, true)
 }
 
+// WhileEach is like While, but uses a fresh predicate from pf every
+// time it runs.
+func (f Statelet) WhileEach(pf PredicateFactory) Statelet {
+	return func(state StateLoader) error {
+		return f.While(pf())(state)
+	}
+}
+
+// UntilEach is like Until, but uses a fresh predicate from pf every
+// time it runs.
+func (f Statelet) UntilEach(pf PredicateFactory) Statelet {
+	return func(state StateLoader) error {
+		return f.Until(pf())(state)
+	}
+}
+
 func (f Statelet) Ite(p Predicate, g, z Statelet) Statelet {
 	return func(state StateLoader) error {
-		if err := f(state); p(err) {
-			return g(state)
+		bg := context.Background()
+		if err := step(bg, at(StepIte, 0), f.Ap(state)); p(err) {
+			return step(bg, at(StepIte, 1), g.Ap(state))
 		} else {
-			return z(state)
+			return step(bg, at(StepIte, 2), z.Ap(state))
 		}
 	}
 }
@@ -118,3 +171,49 @@ func (f Statelet) Once() Statelet {
 		return err
 	}
 }
+
+// Recover converts a panic in f into a *PanicError.
+func (f Statelet) Recover() Statelet {
+	return func(state StateLoader) (err error) {
+		defer recovers(&err)
+		return f(state)
+	}
+}
+
+// Finally runs g after f, even if f fails or panics. If both fail,
+// their errors are returned as a MultiError.
+func (f Statelet) Finally(g Statelet) Statelet {
+	return func(state StateLoader) error {
+		return f.Ap(state).Finally(g.Ap(state))()
+	}
+}
+
+// Bracket runs use and then release if acquire succeeds. release is
+// always run once acquire has succeeded, as with Finally.
+func (acquire Statelet) Bracket(use, release Statelet) Statelet {
+	return func(state StateLoader) error {
+		if err := acquire(state); err != nil {
+			return err
+		}
+		return use.Finally(release)(state)
+	}
+}
+
+// OrElse runs f, then each of gs in turn until one succeeds. If they
+// all fail, their errors are returned as a MultiError.
+func (f Statelet) OrElse(gs ...Statelet) Statelet {
+	return f.OrElseIf(NotNil(), gs...)
+}
+
+// OrElseIf is like OrElse, but only falls back to the next statelet
+// if p holds for the error. Otherwise, that error is returned as is.
+func (f Statelet) OrElseIf(p Predicate, gs ...Statelet) Statelet {
+	return func(state StateLoader) error {
+		fs := make([]Closure, 0, 1+len(gs))
+		fs = append(fs, f.Ap(state))
+		for _, g := range gs {
+			fs = append(fs, g.Ap(state))
+		}
+		return orElse(context.Background(), p, lift(fs...))
+	}
+}
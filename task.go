@@ -0,0 +1,178 @@
+package ftl
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Task is an interruptible function producing a T.
+//
+// Expectations:
+//  1. It returns when the context is cancelled. It doesn't
+//     have to do so immediately, but should at some point.
+type Task[T any] func(context.Context) (T, error)
+
+// LiftTask lifts a Tasklet into a Task that produces the zero
+// value of T on success.
+func LiftTask[T any](f Tasklet) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		var x T
+		return x, f(ctx)
+	}
+}
+
+func (f Task[T]) Run() (T, error) {
+	return f(context.Background())
+}
+
+// Tasklet discards the value produced by f.
+func (f Task[T]) Tasklet() Tasklet {
+	return func(ctx context.Context) error {
+		_, err := f(ctx)
+		return err
+	}
+}
+
+// into returns a Tasklet that stores the value produced by f in x.
+func (f Task[T]) into(x *T) Tasklet {
+	return func(ctx context.Context) error {
+		var err error
+		*x, err = f(ctx)
+		return err
+	}
+}
+
+func (f Task[T]) Ap(ctx context.Context) Fn[T] {
+	return func() (T, error) {
+		return f(ctx)
+	}
+}
+
+// SeqTask runs fs in order, collecting the produced values.
+func SeqTask[T any](fs ...Task[T]) Task[[]T] {
+	return func(ctx context.Context) ([]T, error) {
+		xs := make([]T, 0, len(fs))
+		for i, f := range fs {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			x, err := f.step(ctx, at(StepSeq, i))()
+			if err != nil {
+				return nil, err
+			}
+			xs = append(xs, x)
+		}
+		return xs, nil
+	}
+}
+
+// ParTask runs fs concurrently, cancelling the rest on the first
+// failure. The produced values are in the same order as the
+// arguments.
+func ParTask[T any](fs ...Task[T]) Task[[]T] {
+	return func(ctx context.Context) ([]T, error) {
+		var (
+			eg, taskCtx = errgroup.WithContext(ctx)
+			xs          = make([]T, len(fs))
+		)
+		for i, f := range fs {
+			eg.Go(f.step(taskCtx, at(StepPar, i)).into(&xs[i]))
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+		return xs, nil
+	}
+}
+
+// ParAllTask is like ParTask, but doesn't interrupt anything on
+// failure and waits for every task to return. All of their errors
+// are returned as a MultiError, and the values produced by those
+// that succeeded are kept.
+func ParAllTask[T any](fs ...Task[T]) Task[[]T] {
+	return func(ctx context.Context) ([]T, error) {
+		var (
+			xs = make([]T, len(fs))
+			ts = make([]Tasklet, len(fs))
+		)
+		for i, f := range fs {
+			ts[i] = f.into(&xs[i])
+		}
+		return xs, goAll(ctx, ts)
+	}
+}
+
+func (f Task[T]) cond(p Predicate, exit bool) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		for n := 1; ; n++ {
+			x, err := f.step(ctx, attempt(exit, n))()
+			if p(err) == exit {
+				return x, err
+			}
+		}
+	}
+}
+
+func (f Task[T]) While(p Predicate) Task[T] {
+	return Task[T].cond(f, p, false)
+}
+
+func (f Task[T]) Until(p Predicate) Task[T] {
+	return Task[T].cond(f, p, true)
+}
+
+// WhileEach is like While, but uses a fresh predicate from pf every
+// time it runs.
+func (f Task[T]) WhileEach(pf PredicateFactory) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		return f.While(pf())(ctx)
+	}
+}
+
+// UntilEach is like Until, but uses a fresh predicate from pf every
+// time it runs.
+func (f Task[T]) UntilEach(pf PredicateFactory) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		return f.Until(pf())(ctx)
+	}
+}
+
+// Ite runs f, then g if p holds for its error or z otherwise. The
+// value produced by f is discarded.
+func (f Task[T]) Ite(p Predicate, g, z Task[T]) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		if _, err := f.step(ctx, at(StepIte, 0))(); p(err) {
+			return g.step(ctx, at(StepIte, 1))()
+		} else {
+			return z.step(ctx, at(StepIte, 2))()
+		}
+	}
+}
+
+func (f Task[T]) Mu(mu sync.Locker) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		return f.Ap(ctx).Mu(mu)()
+	}
+}
+
+func (f Task[T]) Once() Task[T] {
+	var (
+		once sync.Once
+		x    T
+		err  error
+	)
+	return func(ctx context.Context) (T, error) {
+		once.Do(func() { x, err = f(ctx) })
+		return x, err
+	}
+}
+
+// Recover is like Tasklet.Recover.
+func (f Task[T]) Recover() Task[T] {
+	return func(ctx context.Context) (x T, err error) {
+		defer recovers(&err)
+		return f(withRecover(ctx))
+	}
+}
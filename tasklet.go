@@ -2,7 +2,9 @@ package ftl
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -28,6 +30,10 @@ func (f Tasklet) Binds(bind func(Tasklet, Tasklet) Tasklet,
 	return x
 }
 
+// ErrTimeout is returned by a Tasklet that was interrupted by its
+// own Timeout or Deadline, rather than by its parent context.
+var ErrTimeout = errors.New("ftl: timeout")
+
 var retErr Tasklet = func(ctx context.Context) error {
 	return ctx.Err()
 }
@@ -38,14 +44,14 @@ func (f Tasklet) Seq(gs ...Tasklet) Tasklet {
 		if err = ctx.Err(); err != nil {
 			return err
 		}
-		if err = f(ctx); err != nil {
+		if err = f.step(ctx, at(StepSeq, 0))(); err != nil {
 			return err
 		}
-		for _, g := range gs {
+		for i, g := range gs {
 			if err = ctx.Err(); err != nil {
 				return err
 			}
-			if err = g(ctx); err != nil {
+			if err = g.step(ctx, at(StepSeq, i+1))(); err != nil {
 				return err
 			}
 		}
@@ -56,14 +62,39 @@ func (f Tasklet) Seq(gs ...Tasklet) Tasklet {
 func (f Tasklet) Par(gs ...Tasklet) Tasklet {
 	return func(ctx context.Context) error {
 		eg, taskCtx := errgroup.WithContext(ctx)
-		eg.Go(f.Ap(taskCtx))
-		for _, g := range gs {
-			eg.Go(g.Ap(taskCtx))
+		eg.Go(f.step(taskCtx, at(StepPar, 0)))
+		for i, g := range gs {
+			eg.Go(g.step(taskCtx, at(StepPar, i+1)))
 		}
 		return eg.Wait()
 	}
 }
 
+// ParN is like Par, but runs at most limit tasklets at once. A limit
+// <= 0 means no limit. Once any of them fails, no more are started.
+func (f Tasklet) ParN(limit int, gs ...Tasklet) Tasklet {
+	return func(ctx context.Context) error {
+		var eg errgroup.Group
+		taskCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		fs := append([]Tasklet{f}, gs...)
+		all := goN(taskCtx, cancel, &eg, limit, fs)
+		if err := eg.Wait(); err != nil || all {
+			return err
+		}
+		return ctx.Err() // didn't start everything
+	}
+}
+
+// ParAll is like Par, but doesn't interrupt anything on failure and
+// waits for every tasklet to return. All of their errors are
+// returned as a MultiError.
+func (f Tasklet) ParAll(gs ...Tasklet) Tasklet {
+	return func(ctx context.Context) error {
+		return goAll(ctx, append([]Tasklet{f}, gs...))
+	}
+}
+
 func (f Tasklet) Ap(ctx context.Context) Closure {
 	return func() error {
 		return f(ctx)
@@ -72,8 +103,8 @@ func (f Tasklet) Ap(ctx context.Context) Closure {
 
 func (f Tasklet) cond(p Predicate, exit bool) Tasklet {
 	return func(ctx context.Context) error {
-		for {
-			if err := f(ctx); p(err) == exit {
+		for n := 1; ; n++ {
+			if err := f.step(ctx, attempt(exit, n))(); p(err) == exit {
 				return err
 			}
 		}
@@ -88,12 +119,28 @@ func (f Tasklet) Until(p Predicate) Tasklet {
 	return Tasklet.cond(f, p, true)
 }
 
+// WhileEach is like While, but uses a fresh predicate from pf every
+// time it runs.
+func (f Tasklet) WhileEach(pf PredicateFactory) Tasklet {
+	return func(ctx context.Context) error {
+		return f.While(pf())(ctx)
+	}
+}
+
+// UntilEach is like Until, but uses a fresh predicate from pf every
+// time it runs.
+func (f Tasklet) UntilEach(pf PredicateFactory) Tasklet {
+	return func(ctx context.Context) error {
+		return f.Until(pf())(ctx)
+	}
+}
+
 func (f Tasklet) Ite(p Predicate, g, z Tasklet) Tasklet {
 	return func(ctx context.Context) error {
-		if err := f(ctx); p(err) {
-			return g(ctx)
+		if err := f.step(ctx, at(StepIte, 0))(); p(err) {
+			return g.step(ctx, at(StepIte, 1))()
 		} else {
-			return z(ctx)
+			return z.step(ctx, at(StepIte, 2))()
 		}
 	}
 }
@@ -113,3 +160,91 @@ func (f Tasklet) Once() Tasklet {
 		return err
 	}
 }
+
+// Recover converts a panic in f into a *PanicError. That includes
+// panics in the branches of any Par, ParN, ParAll, Race, Quorum or
+// Hedge run within f, which would otherwise crash the process.
+func (f Tasklet) Recover() Tasklet {
+	return func(ctx context.Context) (err error) {
+		defer recovers(&err)
+		return f(withRecover(ctx))
+	}
+}
+
+// Deadline interrupts f at time t. If f fails because of that,
+// ErrTimeout is returned.
+func (f Tasklet) Deadline(t time.Time) Tasklet {
+	return func(ctx context.Context) error {
+		fctx, cancel := context.WithDeadline(ctx, t)
+		defer cancel()
+		return timedOut(ctx, fctx, f(fctx))
+	}
+}
+
+// Timeout interrupts f after d. If f fails because of that,
+// ErrTimeout is returned.
+func (f Tasklet) Timeout(d time.Duration) Tasklet {
+	return func(ctx context.Context) error {
+		fctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return timedOut(ctx, fctx, f(fctx))
+	}
+}
+
+// SoftTimeout calls warn if f is still running after soft, and
+// interrupts it after hard like Timeout.
+func (f Tasklet) SoftTimeout(soft, hard time.Duration, warn func()) Tasklet {
+	return func(ctx context.Context) error {
+		t := time.AfterFunc(soft, warn)
+		defer t.Stop()
+		return f.Timeout(hard)(ctx)
+	}
+}
+
+// timedOut returns ErrTimeout in place of err if fctx, but not its
+// parent ctx, has passed its deadline.
+func timedOut(ctx, fctx context.Context, err error) error {
+	if err != nil && ctx.Err() == nil &&
+		fctx.Err() == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return err
+}
+
+// Finally runs g after f, even if f fails, panics or is interrupted.
+// g is given a context that isn't cancelled along with f's. If both
+// fail, their errors are returned as a MultiError.
+func (f Tasklet) Finally(g Tasklet) Tasklet {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			gctx := context.WithoutCancel(ctx)
+			err = join(err, g.step(gctx, at(StepFinally, 1))())
+		}()
+		return f.step(ctx, at(StepFinally, 0))()
+	}
+}
+
+// Bracket runs use and then release if acquire succeeds. release is
+// always run once acquire has succeeded, as with Finally.
+func (acquire Tasklet) Bracket(use, release Tasklet) Tasklet {
+	return func(ctx context.Context) error {
+		if err := acquire(ctx); err != nil {
+			return err
+		}
+		return use.Finally(release)(ctx)
+	}
+}
+
+// OrElse runs f, then each of gs in turn until one succeeds. If they
+// all fail, their errors are returned as a MultiError.
+func (f Tasklet) OrElse(gs ...Tasklet) Tasklet {
+	return f.OrElseIf(NotNil(), gs...)
+}
+
+// OrElseIf is like OrElse, but only falls back to the next tasklet
+// if p holds for the error. Otherwise, that error is returned as is.
+func (f Tasklet) OrElseIf(p Predicate, gs ...Tasklet) Tasklet {
+	return func(ctx context.Context) error {
+		return orElse(ctx, p, append([]Tasklet{f}, gs...))
+	}
+}
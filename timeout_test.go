@@ -0,0 +1,62 @@
+package ftl
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sleeper(d time.Duration) Tasklet {
+	return func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+			return nil
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	t.Run("Timeout", func(t *testing.T) {
+		assert.Equal(t, ErrTimeout, sleeper(time.Second).Timeout(10*time.Millisecond).Run())
+		assert.NoError(t, sleeper(0).Timeout(time.Second).Run())
+	})
+
+	t.Run("Parent cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := sleeper(time.Second).Timeout(time.Second)(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		err := sleeper(time.Second).Deadline(time.Now().Add(10 * time.Millisecond)).Run()
+		assert.Equal(t, ErrTimeout, err)
+	})
+
+	t.Run("SoftTimeout", func(t *testing.T) {
+		var warned int32
+		warn := func() { atomic.StoreInt32(&warned, 1) }
+
+		err := sleeper(20*time.Millisecond).SoftTimeout(5*time.Millisecond, time.Second, warn).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&warned))
+	})
+
+	t.Run("Capped retries", func(t *testing.T) {
+		var tries int
+		err := Tasklet(func(ctx context.Context) error {
+			tries++
+			if tries < 3 {
+				return sleeper(time.Second)(ctx)
+			}
+			return nil
+		}).Timeout(10 * time.Millisecond).Until(Nil()).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, tries)
+	})
+}
@@ -0,0 +1,114 @@
+package ftl
+
+import (
+	"sync"
+	"time"
+)
+
+// now is the clock used by time based predicates.
+var now = time.Now
+
+// Elapsed holds once d has passed since it was first evaluated.
+//
+// To retry for at most 30s:
+//
+//	f.While(NotNil().And(Elapsed(30 * time.Second).Not()))
+func Elapsed(d time.Duration) Predicate {
+	var (
+		once  sync.Once
+		start time.Time
+	)
+	return func(_ error) bool {
+		once.Do(func() { start = now() })
+		return now().Sub(start) >= d
+	}
+}
+
+// Before holds until t.
+func Before(t time.Time) Predicate {
+	return func(_ error) bool {
+		return now().Before(t)
+	}
+}
+
+// After holds from t onwards.
+func After(t time.Time) Predicate {
+	return func(_ error) bool {
+		return !now().Before(t)
+	}
+}
+
+// Window is a span of time that recurs every day, or every week on
+// some days.
+type Window struct {
+	// Days the window starts on. Every day if empty.
+	Days []time.Weekday
+
+	// Start and End of the window, as offsets from midnight. If End
+	// is before Start, the window runs past midnight into the next
+	// day.
+	Start, End time.Duration
+
+	// Location the window is in. time.Local if nil.
+	Location *time.Location
+}
+
+// Daily returns a window from start to end every day.
+func Daily(start, end time.Duration) Window {
+	return Window{Start: start, End: end}
+}
+
+// Weekly returns a window from start to end on each of days.
+func Weekly(start, end time.Duration, days ...time.Weekday) Window {
+	return Window{Days: days, Start: start, End: end}
+}
+
+// Contains reports whether t falls inside the window.
+func (w Window) Contains(t time.Time) bool {
+	loc := w.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+
+	// by the clock, which isn't the time since midnight on days
+	// that daylight saving time starts or ends
+	h, m, s := t.Clock()
+	off := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+
+	if w.Start <= w.End {
+		return w.on(t.Weekday()) && w.Start <= off && off < w.End
+	}
+
+	// past midnight, the window belongs to the day before
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.on(t.Weekday()) && w.Start <= off) ||
+		(w.on(yesterday) && off < w.End)
+}
+
+// on reports whether the window starts on day.
+func (w Window) on(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, x := range w.Days {
+		if x == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Within holds while the current time is inside any of ws.
+func Within(ws ...Window) Predicate {
+	return func(_ error) bool {
+		t := now()
+		for _, w := range ws {
+			if w.Contains(t) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,65 @@
+package ftl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhen(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		// 2024-01-01 was a monday
+		return time.Date(2024, 1, day, hour, 30, 0, 0, time.UTC)
+	}
+
+	t.Run("Elapsed", func(t *testing.T) {
+		var i int
+		err := Closure(succ(&i)).Until(Elapsed(10 * time.Millisecond))()
+		assert.NoError(t, err)
+		assert.True(t, i > 1)
+	})
+
+	t.Run("Before and After", func(t *testing.T) {
+		ts := time.Now().Add(time.Hour)
+		assert.True(t, Before(ts)(nil))
+		assert.False(t, After(ts)(nil))
+	})
+
+	t.Run("Window", func(t *testing.T) {
+		w := Daily(2*time.Hour, 4*time.Hour)
+		w.Location = time.UTC
+		assert.True(t, w.Contains(at(1, 3)))
+		assert.False(t, w.Contains(at(1, 4)))
+
+		w = Weekly(22*time.Hour, 2*time.Hour, time.Saturday)
+		w.Location = time.UTC
+		assert.True(t, w.Contains(at(6, 23)))  // saturday night
+		assert.True(t, w.Contains(at(7, 1)))   // sunday morning
+		assert.False(t, w.Contains(at(7, 23))) // sunday night
+		assert.False(t, w.Contains(at(6, 1)))  // saturday morning
+	})
+
+	t.Run("Window on DST change", func(t *testing.T) {
+		ny, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skip(err)
+		}
+		w := Daily(10*time.Hour, 11*time.Hour)
+		w.Location = ny
+
+		// clocks went forward at 2am, so it's 9h30 since midnight
+		assert.True(t, w.Contains(time.Date(2024, 3, 10, 10, 30, 0, 0, ny)))
+		assert.False(t, w.Contains(time.Date(2024, 3, 10, 9, 30, 0, 0, ny)))
+	})
+
+	t.Run("Within", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		now = func() time.Time { return at(1, 3) }
+
+		w := Daily(2*time.Hour, 4*time.Hour)
+		w.Location = time.UTC
+		assert.True(t, Within(Daily(0, 0), w)(nil))
+		assert.False(t, Within()(nil))
+	})
+}